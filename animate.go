@@ -0,0 +1,135 @@
+package hd44780
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Animate calls each of the frame functions in turn, one per interval, looping back to the first frame after
+// the last. The first frame is drawn immediately. Animate blocks until ctx is cancelled, in which case it returns
+// nil, or until a frame returns an error, which is returned as is.
+func Animate(frames []func() error, interval time.Duration, ctx context.Context) error {
+	if len(frames) == 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for i := 0; ; i = (i + 1) % len(frames) {
+		err := frames[i]()
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// BlinkRegion makes text at col on line blink by writing it and then spaces over it, alternating each interval. It
+// blocks until ctx is cancelled, the text is left showing when it returns.
+func (hd *Hd44780I2c) BlinkRegion(line, col byte, text string, interval time.Duration, ctx context.Context) error {
+	blank := strings.Repeat(" ", len([]rune(text)))
+	frames := []func() error{
+		func() error { return hd.DisplayString(text, line, col) },
+		func() error { return hd.DisplayString(blank, line, col) },
+	}
+
+	err := Animate(frames, interval, ctx)
+	if err != nil {
+		return err
+	}
+	return hd.DisplayString(text, line, col)
+}
+
+// TypeString displays text at the specified position one character at a time with charDelay between each, like a
+// typewriter. If ctx is cancelled before all of text has been written ctx.Err() is returned.
+func (hd *Hd44780I2c) TypeString(text string, line, pos byte, charDelay time.Duration, ctx context.Context) error {
+	err := hd.SetCursor(line, pos)
+	if err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for _, c := range text {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		err = hd.WriteChar(hd.charCode(c))
+		if err != nil {
+			return err
+		}
+		timer.Reset(charDelay)
+	}
+	return nil
+}
+
+// BackslashChar is a backslash as a custom character, the A00 character ROM has the yen sign where ASCII has a
+// backslash. Load it into a custom character slot to use it in SpinnerFrames.
+var BackslashChar = CustomChar{0x00, 0x10, 0x08, 0x04, 0x02, 0x01, 0x00, 0x00}
+
+// Spinner shows a spinner made of |, / and - in the cell at col on line, changing every interval, until ctx is
+// cancelled. See SpinnerFrames.
+func (hd *Hd44780I2c) Spinner(line, col byte, interval time.Duration, ctx context.Context) error {
+	return hd.SpinnerFrames([]byte{'|', '/', '-'}, line, col, interval, ctx)
+}
+
+// SpinnerFrames shows each of the character codes in frames in turn in the cell at col on line, changing every
+// interval, eg []byte{'|', '/', '-', slot} with BackslashChar loaded into slot. It blocks until ctx is cancelled so
+// it's usually run in its own goroutine, each frame is drawn in a Transaction so other goroutines should write to
+// the display in transactions too. The cursor is put back where it was after each frame and the cell is restored to
+// the character that was there before it returns.
+func (hd *Hd44780I2c) SpinnerFrames(frames []byte, line, col byte, interval time.Duration, ctx context.Context) error {
+	var prev byte
+	err := hd.Transaction(func(tx *Tx) error {
+		addr, ctrl, err := tx.address(line, col)
+		prev = tx.ddram[ctrl][addr&0x7f]
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	drawFrames := make([]func() error, len(frames))
+	for i, code := range frames {
+		code := code
+		drawFrames[i] = func() error {
+			return hd.Transaction(func(tx *Tx) error { return tx.writeCell(line, col, code) })
+		}
+	}
+	err = Animate(drawFrames, interval, ctx)
+	if err != nil {
+		return err
+	}
+	return hd.Transaction(func(tx *Tx) error { return tx.writeCell(line, col, prev) })
+}
+
+// writeCell writes code to the cell at col on line and puts the cursor back where it was.
+func (hd *Hd44780I2c) writeCell(line, col, code byte) error {
+	row, curCol, ctrl := hd.curRow, hd.curCol, hd.ctrl
+	err := hd.SetCursor(line, col)
+	if err != nil {
+		return err
+	}
+	err = hd.WriteChar(code)
+	if err != nil {
+		return err
+	}
+
+	hd.ctrl = ctrl
+	err = hd.WriteInstruction(lcdSetDDRamAddr | hd.cellAddress(row, curCol))
+	if err != nil {
+		return err
+	}
+	hd.curRow, hd.curCol = row, curCol
+	return nil
+}
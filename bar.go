@@ -0,0 +1,100 @@
+package hd44780
+
+import (
+	"fmt"
+	"math"
+)
+
+// TextBar draws a horizontal bar width characters wide starting at col on line, the first fraction of it is filled
+// with FullBlock characters and the rest with spaces. It's coarser than a bar made from custom characters, a cell is
+// either full or empty (fraction is rounded to the nearest cell), but it doesn't use any custom character slots.
+// fraction is clamped to 0 - 1.
+func (hd *Hd44780I2c) TextBar(line, col, width byte, fraction float64) error {
+	if int(col)+int(width) > int(hd.cols) {
+		return fmt.Errorf("%w: bar from %d to %d", ErrInvalidPos, col, int(col)+int(width)-1)
+	}
+
+	full := int(math.Round(clamp(fraction, 0, 1) * float64(width)))
+	bar := make([]byte, width)
+	for i := range bar {
+		bar[i] = ' '
+		if i < full {
+			bar[i] = FullBlock
+		}
+	}
+	return hd.DisplayBytes(bar, line, col)
+}
+
+// centerBarChars are the partly filled cells of a CenterBar, 1 - 4 columns filled from the left (slots 0 - 3) then
+// from the right (slots 4 - 7).
+var centerBarChars = [8]CustomChar{
+	{0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10},
+	{0x18, 0x18, 0x18, 0x18, 0x18, 0x18, 0x18, 0x18},
+	{0x1c, 0x1c, 0x1c, 0x1c, 0x1c, 0x1c, 0x1c, 0x1c},
+	{0x1e, 0x1e, 0x1e, 0x1e, 0x1e, 0x1e, 0x1e, 0x1e},
+	{0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01},
+	{0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03},
+	{0x07, 0x07, 0x07, 0x07, 0x07, 0x07, 0x07, 0x07},
+	{0x0f, 0x0f, 0x0f, 0x0f, 0x0f, 0x0f, 0x0f, 0x0f},
+}
+
+// CenterBar draws a bar width characters wide starting at col on line that fills from the middle, left for a
+// negative value and right for a positive one, eg for a balance or VU meter. value is clamped to -1 - 1, at -1 the
+// left half is full and at 1 the right half is. The bar fills a column of pixels at a time using partly filled
+// custom characters, which are loaded into all 8 slots (overwriting any custom characters already loaded) the first
+// time it's drawn and again if the custom characters have been replaced since. If width is odd the right half is a
+// character wider than the left.
+func (hd *Hd44780I2c) CenterBar(line, col, width byte, value float64) error {
+	if int(col)+int(width) > int(hd.cols) {
+		return fmt.Errorf("%w: bar from %d to %d", ErrInvalidPos, col, int(col)+int(width)-1)
+	}
+
+	value = clamp(value, -1, 1)
+	half := int(width / 2)
+	// the filled pixel columns, counted from the left of the bar
+	var from, to int
+	if value < 0 {
+		to = half * 5
+		from = to - int(math.Round(-value*float64(half*5)))
+	} else {
+		from = half * 5
+		to = from + int(math.Round(value*float64((int(width)-half)*5)))
+	}
+
+	bar := make([]byte, width)
+	for i := range bar {
+		start, end := i*5, i*5+5
+		filled := minInt(end, to) - maxInt(start, from)
+		switch {
+		case filled <= 0:
+			bar[i] = ' '
+		case filled == 5:
+			bar[i] = FullBlock
+		case from > start:
+			// filled from the right, the left end of the bar
+			bar[i] = byte(4 + filled - 1)
+		default:
+			bar[i] = byte(filled - 1)
+		}
+	}
+
+	if !hd.barChars {
+		err := hd.LoadCustomChars(centerBarChars)
+		if err != nil {
+			return err
+		}
+		hd.barChars = true
+	}
+	return hd.DisplayBytes(bar, line, col)
+}
+
+// clamp limits v to min - max, NaN is treated as min.
+func clamp(v, min, max float64) float64 {
+	switch {
+	case math.IsNaN(v) || v < min:
+		return min
+	case v > max:
+		return max
+	}
+	return v
+}
@@ -0,0 +1,68 @@
+package hd44780
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCenterBar(t *testing.T) {
+	hd, bus := newTestDisplay(t)
+
+	tests := []struct {
+		value float64
+		want  string
+	}{
+		{0.5, "  \xff "},
+		{-0.3, " \x06  "},
+		{0.7, "  \xff\x01"},
+		{-2, "\xff\xff  "},
+		{0, "    "},
+	}
+	for i, tt := range tests {
+		err := hd.CenterBar(1, 2, 4, tt.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(hd.ddram[0][0x42:0x46]); got != tt.want {
+			t.Errorf("%v: got %q, want %q", tt.value, got, tt.want)
+		}
+		// the custom characters are only loaded the first time
+		if loads := countCGRAMLoads(bus.instructions(hd.PinMap)); (i == 0) != (loads > 0) {
+			t.Errorf("%v: loaded the custom characters %d times", tt.value, loads)
+		}
+		bus.written = nil
+	}
+}
+
+func TestTextBar(t *testing.T) {
+	hd, bus := newTestDisplay(t)
+
+	tests := []struct {
+		fraction float64
+		want     string
+	}{
+		{0, "     "},
+		{0.5, "\xff\xff\xff  "},
+		{0.3, "\xff\xff   "},
+		{1, "\xff\xff\xff\xff\xff"},
+		{1.5, "\xff\xff\xff\xff\xff"},
+		{-1, "     "},
+	}
+	for _, tt := range tests {
+		err := hd.TextBar(0, 11, 5, tt.fraction)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(hd.ddram[0][0x0b:0x10]); got != tt.want {
+			t.Errorf("%v: got %q, want %q", tt.fraction, got, tt.want)
+		}
+	}
+	if n := countCGRAMLoads(bus.instructions(hd.PinMap)); n != 0 {
+		t.Errorf("got %d custom characters loaded, want none", n)
+	}
+
+	err := hd.TextBar(0, 12, 5, 1)
+	if !errors.Is(err, ErrInvalidPos) {
+		t.Errorf("got %v for a bar past the edge, want ErrInvalidPos", err)
+	}
+}
@@ -0,0 +1,101 @@
+package hd44780
+
+import "fmt"
+
+// segment slots in CGRAM used by BigDigits
+const (
+	segLeftTop byte = iota
+	segUpperBar
+	segRightTop
+	segLeftLow
+	segLowerBar
+	segRightLow
+	segUpperMiddleBars
+	segLowerMiddleBars
+
+	blank byte = ' '
+)
+
+// bigDigitSegments are the shapes that all big digits are built from, they use all 8 CGRAM slots.
+var bigDigitSegments = [8]CustomChar{
+	segLeftTop:         {0x07, 0x0f, 0x1f, 0x1f, 0x1f, 0x1f, 0x1f, 0x1f},
+	segUpperBar:        {0x1f, 0x1f, 0x1f, 0x00, 0x00, 0x00, 0x00, 0x00},
+	segRightTop:        {0x1c, 0x1e, 0x1f, 0x1f, 0x1f, 0x1f, 0x1f, 0x1f},
+	segLeftLow:         {0x1f, 0x1f, 0x1f, 0x1f, 0x1f, 0x1f, 0x0f, 0x07},
+	segLowerBar:        {0x00, 0x00, 0x00, 0x00, 0x00, 0x1f, 0x1f, 0x1f},
+	segRightLow:        {0x1f, 0x1f, 0x1f, 0x1f, 0x1f, 0x1f, 0x1e, 0x1c},
+	segUpperMiddleBars: {0x1f, 0x1f, 0x1f, 0x00, 0x00, 0x00, 0x1f, 0x1f},
+	segLowerMiddleBars: {0x1f, 0x00, 0x00, 0x00, 0x00, 0x1f, 0x1f, 0x1f},
+}
+
+// bigDigits are the top and bottom rows of each big character.
+var bigDigits = map[rune][2][]byte{
+	'0': {{segLeftTop, segUpperBar, segRightTop}, {segLeftLow, segLowerBar, segRightLow}},
+	'1': {{segUpperBar, segRightTop, blank}, {segLowerBar, FullBlock, segLowerBar}},
+	'2': {{segUpperMiddleBars, segUpperMiddleBars, segRightTop}, {segLeftLow, segLowerBar, segLowerBar}},
+	'3': {{segUpperMiddleBars, segUpperMiddleBars, segRightTop}, {segLowerMiddleBars, segLowerMiddleBars, segRightLow}},
+	'4': {{segLeftLow, segLowerBar, FullBlock}, {blank, blank, FullBlock}},
+	'5': {{FullBlock, segUpperMiddleBars, segUpperMiddleBars}, {segLowerMiddleBars, segLowerMiddleBars, segRightLow}},
+	'6': {{segLeftTop, segUpperMiddleBars, segUpperMiddleBars}, {segLeftLow, segLowerMiddleBars, segRightLow}},
+	'7': {{segUpperBar, segUpperBar, segRightTop}, {blank, blank, FullBlock}},
+	'8': {{segLeftTop, segUpperMiddleBars, segRightTop}, {segLeftLow, segLowerMiddleBars, segRightLow}},
+	'9': {{segLeftTop, segUpperMiddleBars, segRightTop}, {blank, blank, FullBlock}},
+	':': {{MiddleDot}, {MiddleDot}},
+	' ': {{blank, blank, blank}, {blank, blank, blank}},
+}
+
+// BigDigits displays digits that are 2 rows tall, eg for a clock. Each digit (and space) is 3 columns wide, a ':' is
+// 1 column wide and there's a blank column between characters, so "12:34" takes 17 columns.
+//
+// All the digits are made from the same 8 segments which are loaded into CGRAM the first time Display is called,
+// overwriting any custom characters already loaded.
+type BigDigits struct {
+	hd     *Hd44780I2c
+	loaded bool
+}
+
+// NewBigDigits returns a BigDigits that displays on hd.
+func NewBigDigits(hd *Hd44780I2c) *BigDigits {
+	return &BigDigits{hd: hd}
+}
+
+// Load stores the digit segments in CGRAM, it only needs to be called if the custom characters have been replaced
+// since the last call to Display.
+func (bd *BigDigits) Load() error {
+	err := bd.hd.LoadCustomChars(bigDigitSegments)
+	if err != nil {
+		return err
+	}
+	bd.loaded = true
+	return nil
+}
+
+// Display displays text, which can contain digits, ':' and spaces, on line and the line below it starting at col.
+func (bd *BigDigits) Display(text string, line, col byte) error {
+	var top, bottom []byte
+	for _, r := range text {
+		d, ok := bigDigits[r]
+		if !ok {
+			return fmt.Errorf("hd44780: no big digit for %q", r)
+		}
+		if len(top) > 0 {
+			top = append(top, blank)
+			bottom = append(bottom, blank)
+		}
+		top = append(top, d[0]...)
+		bottom = append(bottom, d[1]...)
+	}
+
+	if !bd.loaded {
+		err := bd.Load()
+		if err != nil {
+			return err
+		}
+	}
+
+	err := bd.hd.DisplayBytes(top, line, col)
+	if err != nil {
+		return err
+	}
+	return bd.hd.DisplayBytes(bottom, line+1, col)
+}
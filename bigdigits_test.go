@@ -0,0 +1,41 @@
+package hd44780
+
+import "testing"
+
+func TestBigDigits(t *testing.T) {
+	hd, bus := newTestDisplay(t)
+	bd := NewBigDigits(hd)
+
+	err := bd.Display("1:2", 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := countCGRAMLoads(bus.instructions(hd.PinMap)); n == 0 {
+		t.Error("the segments weren't loaded")
+	}
+	if got, want := string(hd.ddram[0][0x01:0x0a]), "\x01\x02  \xa5 \x06\x06\x02"; got != want {
+		t.Errorf("got top row %q, want %q", got, want)
+	}
+	if got, want := string(hd.ddram[0][0x41:0x4a]), "\x04\xff\x04 \xa5 \x03\x04\x04"; got != want {
+		t.Errorf("got bottom row %q, want %q", got, want)
+	}
+
+	// the segments are only loaded the first time
+	bus.written = nil
+	err = bd.Display("7", 0, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := countCGRAMLoads(bus.instructions(hd.PinMap)); n != 0 {
+		t.Errorf("got %d custom characters loaded the second time, want 0", n)
+	}
+
+	bus.written = nil
+	err = bd.Display("1a", 0, 0)
+	if err == nil {
+		t.Error("got no error for a character without a big digit")
+	}
+	if len(bus.written) > 0 {
+		t.Errorf("got %d writes for a character without a big digit, want nothing sent", len(bus.written))
+	}
+}
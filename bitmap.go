@@ -0,0 +1,46 @@
+package hd44780
+
+import "fmt"
+
+// DrawBitmap displays an image made of several custom characters, cells[0] is the top row of the image and
+// cells[0][0] its top left character which is put at startCol on startLine. Identical cells share a custom
+// character, ErrTooManyCustomChars is returned if there are more than 8 different cells (4 in 5x10-pixel character
+// mode). The custom characters are loaded starting from slot 0, replacing any that were there.
+func (hd *Hd44780I2c) DrawBitmap(cells [][]CustomChar, startLine, startCol byte) error {
+	maxSlots := 8
+	if hd.Dots5x10Enabled() {
+		maxSlots = 4
+	}
+
+	slots := make(map[CustomChar]byte)
+	var glyphs []CustomChar
+	codes := make([][]byte, len(cells))
+	for row, line := range cells {
+		for _, c := range line {
+			slot, ok := slots[c]
+			if !ok {
+				if len(glyphs) == maxSlots {
+					return fmt.Errorf("%w: bitmap has more than %d different cells", ErrTooManyCustomChars, maxSlots)
+				}
+				slot = byte(len(glyphs))
+				slots[c] = slot
+				glyphs = append(glyphs, c)
+			}
+			codes[row] = append(codes[row], hd.customCharCode(slot))
+		}
+	}
+
+	for slot, c := range glyphs {
+		err := hd.SetCustomChar(byte(slot), c)
+		if err != nil {
+			return err
+		}
+	}
+	for row, line := range codes {
+		err := hd.DisplayBytes(line, startLine+byte(row), startCol)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
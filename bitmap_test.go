@@ -0,0 +1,37 @@
+package hd44780
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDrawBitmap(t *testing.T) {
+	hd, bus := newTestDisplay(t)
+	a, b, c := CustomChar{0x01}, CustomChar{0x02}, CustomChar{0x03}
+
+	// identical cells share a slot, the slots are given out in order from the top left
+	err := hd.DrawBitmap([][]CustomChar{{a, b, a}, {c, a, b}}, 0, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(hd.ddram[0][0x05:0x08]) + string(hd.ddram[0][0x45:0x48]); got != "\x00\x01\x00\x02\x00\x01" {
+		t.Errorf("got %q displayed, want %q", got, "\x00\x01\x00\x02\x00\x01")
+	}
+	if n := countCGRAMLoads(bus.instructions(hd.PinMap)); n != 3 {
+		t.Errorf("got %d custom characters loaded, want 3", n)
+	}
+
+	// a 9th different cell doesn't fit and nothing is sent
+	bus.written = nil
+	var cells [][]CustomChar
+	for i := 0; i < 9; i++ {
+		cells = append(cells, []CustomChar{{byte(i)}})
+	}
+	err = hd.DrawBitmap(cells, 0, 0)
+	if !errors.Is(err, ErrTooManyCustomChars) {
+		t.Errorf("got %v with 9 different cells, want ErrTooManyCustomChars", err)
+	}
+	if len(bus.written) > 0 {
+		t.Errorf("got %d writes for a bitmap that doesn't fit, want nothing sent", len(bus.written))
+	}
+}
@@ -0,0 +1,189 @@
+package hd44780
+
+import (
+	"fmt"
+	"time"
+)
+
+// BusWriter sends bytes to the port expander that the HD44780 is connected to, each byte sets all of the expander's
+// output pins. *i2c.I2C from github.com/d2r2/go-i2c is a BusWriter. If the bus also implements io.Reader it's used
+// for reads and if it implements io.Closer it's closed by Close when CloseBus is set.
+type BusWriter interface {
+	Write(buf []byte) (int, error)
+}
+
+// writeByte writes a single byte to the bus.
+func (hd *Hd44780I2c) writeByte(b byte) error {
+	return hd.busWrite([]byte{b})
+}
+
+// writePins sets the port expander's pins, all 16 pins are written in 8-bit mode (as a 16-bit expander is required)
+// otherwise just the first 8.
+func (hd *Hd44780I2c) writePins(pins uint16) error {
+	if hd.EightBitModeEnabled() {
+		return hd.busWrite([]byte{byte(pins), byte(pins >> 8)})
+	}
+	return hd.writeByte(byte(pins))
+}
+
+// busWrite writes buf to the bus, retrying according to the retry policy if it fails. ErrTimeout is returned rather
+// than writing if the operation in progress has run past its deadline or an earlier write that timed out hasn't
+// returned yet.
+func (hd *Hd44780I2c) busWrite(buf []byte) error {
+	err := hd.checkStuck()
+	if err != nil {
+		return err
+	}
+	err = hd.checkDeadline()
+	if err != nil {
+		return err
+	}
+	err = hd.writeWithDeadline(buf)
+	backoff := hd.retryBackoff
+	for i := 0; err != nil && err != ErrTimeout && i < hd.retries; i++ {
+		time.Sleep(backoff)
+		backoff *= 2
+		if derr := hd.checkDeadline(); derr != nil {
+			return derr
+		}
+		err = hd.writeWithDeadline(buf)
+	}
+	return err
+}
+
+// writeWithDeadline writes buf to the bus, if there's a deadline the write is done in another goroutine so that a
+// write that blocks (eg on a wedged bus) can be given up on when the deadline passes. The write carries on in the
+// background and hd.stuck is closed once it returns, until then nothing else is sent, see checkStuck.
+func (hd *Hd44780I2c) writeWithDeadline(buf []byte) error {
+	if hd.opDeadline.IsZero() {
+		_, err := hd.bus.Write(buf)
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := hd.bus.Write(buf)
+		done <- err
+	}()
+	timer := time.NewTimer(time.Until(hd.opDeadline))
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		stuck := make(chan struct{})
+		go func() {
+			<-done
+			close(stuck)
+		}()
+		hd.stuck = stuck
+		hd.needsResync = true
+		return ErrTimeout
+	}
+}
+
+// checkStuck returns ErrTimeout if a write that timed out is still in progress, the bus can't be used again until
+// it's returned.
+func (hd *Hd44780I2c) checkStuck() error {
+	if hd.stuck == nil {
+		return nil
+	}
+	select {
+	case <-hd.stuck:
+		hd.stuck = nil
+		return nil
+	default:
+		return fmt.Errorf("%w: an earlier write that timed out hasn't returned", ErrTimeout)
+	}
+}
+
+// startOp starts the deadline of a public operation that writes to the display if SetOpTimeout has been used, the
+// function returned ends it. An operation started by another one (eg DisplayString calling WriteChar) is part of the
+// outer one so it shares its deadline.
+func (hd *Hd44780I2c) startOp() (end func()) {
+	if hd.opTimeout == 0 || !hd.opDeadline.IsZero() {
+		return func() {}
+	}
+	hd.opDeadline = time.Now().Add(hd.opTimeout)
+	return func() { hd.opDeadline = time.Time{} }
+}
+
+// checkDeadline returns ErrTimeout if the operation in progress has run past its deadline, the operation may be
+// stopped between the 2 nibbles of a byte so the display is marked as needing Resync.
+func (hd *Hd44780I2c) checkDeadline() error {
+	if hd.opDeadline.IsZero() || time.Now().Before(hd.opDeadline) {
+		return nil
+	}
+	hd.needsResync = true
+	return ErrTimeout
+}
+
+// SetOpTimeout sets how long an operation that writes to the display (eg DisplayString or LoadCustomChars) can take
+// before it's abandoned and ErrTimeout returned, 0 (the default) means no timeout. With a timeout each write to the
+// bus is made in its own goroutine so a write that blocks, eg on a wedged bus, is given up on at the deadline. The
+// write that's given up on still holds the bus, every write (and read) until it returns fails with ErrTimeout.
+//
+// An operation that's abandoned can stop part way through an instruction, in 4-bit mode that leaves the controller
+// out of step with the nibbles so everything after it is garbage. NeedsResync reports true from then on, call Resync
+// (and rewrite what was being written) before carrying on.
+func (hd *Hd44780I2c) SetOpTimeout(d time.Duration) {
+	hd.opTimeout = d
+}
+
+// NeedsResync reports whether an operation has been abandoned with ErrTimeout since the display was last
+// resynchronised with Resync or initialised with Reinit, see SetOpTimeout.
+func (hd *Hd44780I2c) NeedsResync() bool {
+	return hd.needsResync
+}
+
+// RetryWrites returns a ModeSetter that makes a failed bus write be retried up to retries times before the error is
+// returned, the first retry is after backoff and the wait doubles for each retry after that.
+//
+// Each write to the bus sets the state of the port expander's pins so it's the individual writes (not whole
+// instructions) that are retried. The controller latches a nibble when EN goes low, if a whole instruction was
+// resent after the first nibble had been latched the controller would get 3 nibbles and be out of step, whereas
+// resending the same pin state is harmless.
+func RetryWrites(retries int, backoff time.Duration) ModeSetter {
+	return func(hd *Hd44780I2c) {
+		hd.retries = retries
+		hd.retryBackoff = backoff
+	}
+}
+
+// PeriphConn is the transaction method of a periph.io connection, it's implemented by *i2c.Dev from
+// periph.io/x/conn/v3/i2c.
+type PeriphConn interface {
+	Tx(w, r []byte) error
+}
+
+// PeriphBus adapts a periph.io connection to a BusWriter, every Write or Read is a separate transaction.
+type PeriphBus struct {
+	Conn PeriphConn
+}
+
+// Write implements BusWriter.
+func (p PeriphBus) Write(buf []byte) (int, error) {
+	err := p.Conn.Tx(buf, nil)
+	if err != nil {
+		return 0, err
+	}
+	return len(buf), nil
+}
+
+// Read implements io.Reader.
+func (p PeriphBus) Read(buf []byte) (int, error) {
+	err := p.Conn.Tx(nil, buf)
+	if err != nil {
+		return 0, err
+	}
+	return len(buf), nil
+}
+
+// NewHd44780Periph returns a new Connection based on a periph.io I²C device, eg
+//
+//	dev := &i2c.Dev{Bus: bus, Addr: 0x27}
+//	lcd, err := hd44780.NewHd44780Periph(dev, hd44780.PCF8574PinMap, hd44780.RowAddress16Col)
+func NewHd44780Periph(conn PeriphConn, pinMap I2CPinMap, rowAddr RowAddress, modes ...ModeSetter) (*Hd44780I2c, error) {
+	return NewHd44780(PeriphBus{Conn: conn}, pinMap, rowAddr, modes...)
+}
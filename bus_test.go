@@ -0,0 +1,345 @@
+package hd44780
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeBus records everything written to it, the first failures writes return an error as do all writes once failAt
+// bytes have been written.
+type fakeBus struct {
+	written  []byte
+	failures int
+	failAt   int
+}
+
+func (b *fakeBus) Write(buf []byte) (int, error) {
+	if b.failures > 0 || (b.failAt > 0 && len(b.written) >= b.failAt) {
+		b.failures--
+		return 0, errors.New("nak")
+	}
+	b.written = append(b.written, buf...)
+	return len(buf), nil
+}
+
+// newTestDisplay returns a display on a fakeBus, the bytes written during construction are discarded.
+func newTestDisplay(t *testing.T, modes ...ModeSetter) (*Hd44780I2c, *fakeBus) {
+	t.Helper()
+	bus := &fakeBus{}
+	hd, err := NewHd44780(bus, PCF8574PinMap, RowAddress16Col, append([]ModeSetter{SkipInit}, modes...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus.written = nil
+	return hd, bus
+}
+
+// instruction is a byte sent to the controller.
+type instruction struct {
+	rs   RegisterSelect
+	data byte
+}
+
+// instructions decodes the 4-bit mode writes into the bytes the controller received, a nibble is read each time EN
+// goes high.
+func (b *fakeBus) instructions(pm I2CPinMap) []instruction {
+	var ins []instruction
+	var high bool
+	var last byte
+	for _, w := range b.written {
+		if w&(0x01<<pm.EN) > 0 && last&(0x01<<pm.EN) == 0 {
+			var nibble byte
+			for i, pin := range []byte{pm.D4, pm.D5, pm.D6, pm.D7} {
+				nibble |= ((w >> pin) & 0x01) << i
+			}
+			if high {
+				ins[len(ins)-1].data |= nibble
+			} else {
+				ins = append(ins, instruction{RegisterSelect((w >> pm.RS) & 0x01), nibble << 4})
+			}
+			high = !high
+		}
+		last = w
+	}
+	return ins
+}
+
+func TestNewHd44780(t *testing.T) {
+	bus := &fakeBus{}
+	_, err := NewHd44780(bus, PCF8574PinMap, RowAddress16Col)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []instruction{
+		{registerSelectLow, 0x03},
+		{registerSelectLow, 0x03},
+		{registerSelectLow, 0x03},
+		{registerSelectLow, 0x02},
+		{registerSelectLow, lcdClearDisplay},
+		{registerSelectLow, 0x06},
+		{registerSelectLow, 0x0c},
+		{registerSelectLow, 0x28},
+	}
+	got := bus.instructions(PCF8574PinMap)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got instructions %#v, want %#v", got, want)
+	}
+}
+
+func TestRetryWrites(t *testing.T) {
+	tests := []struct {
+		retries, failures int
+		fail              bool
+	}{
+		{0, 0, false},
+		{0, 1, true},
+		{2, 2, false},
+		{2, 3, true},
+	}
+
+	for _, tt := range tests {
+		bus := &fakeBus{failures: tt.failures}
+		hd := &Hd44780I2c{bus: bus, PinMap: PCF8574PinMap}
+		RetryWrites(tt.retries, time.Microsecond)(hd)
+
+		err := hd.WriteChar('a')
+		if (err != nil) != tt.fail {
+			t.Errorf("%d retries, %d failures: got error %v", tt.retries, tt.failures, err)
+		}
+		if !tt.fail && !reflect.DeepEqual(bus.instructions(PCF8574PinMap), []instruction{{registerSelectHigh, 'a'}}) {
+			t.Errorf("%d retries, %d failures: got instructions %#v", tt.retries, tt.failures, bus.instructions(PCF8574PinMap))
+		}
+	}
+}
+
+// fakePeriphConn records the bytes of each transaction.
+type fakePeriphConn struct {
+	w    []byte
+	read []byte
+}
+
+func (c *fakePeriphConn) Tx(w, r []byte) error {
+	c.w = append(c.w, w...)
+	copy(r, c.read)
+	return nil
+}
+
+func TestPeriphBus(t *testing.T) {
+	conn := &fakePeriphConn{read: []byte{0x12, 0x34}}
+	bus := PeriphBus{Conn: conn}
+
+	n, err := bus.Write([]byte{0x01, 0x02})
+	if err != nil || n != 2 {
+		t.Errorf("write: got %d, %v", n, err)
+	}
+	if !bytes.Equal(conn.w, []byte{0x01, 0x02}) {
+		t.Errorf("got %#v written", conn.w)
+	}
+
+	buf := make([]byte, 2)
+	n, err = bus.Read(buf)
+	if err != nil || n != 2 || !bytes.Equal(buf, conn.read) {
+		t.Errorf("read: got %d, %#v, %v", n, buf, err)
+	}
+}
+
+func TestInitError(t *testing.T) {
+	tests := []struct {
+		bus   *fakeBus
+		stage error
+	}{
+		{&fakeBus{failures: 1}, ErrBusUnreachable},
+		{&fakeBus{failAt: 10}, ErrInitFailed},
+	}
+
+	for _, tt := range tests {
+		_, err := NewHd44780(tt.bus, PCF8574PinMap, RowAddress16Col)
+		if !errors.Is(err, tt.stage) {
+			t.Errorf("got %v, want %v", err, tt.stage)
+		}
+		if errors.Unwrap(err) == nil || errors.Unwrap(err).Error() != "nak" {
+			t.Errorf("got unwrapped error %v", errors.Unwrap(err))
+		}
+	}
+}
+
+// slowBus is a fakeBus where every write takes delay.
+type slowBus struct {
+	fakeBus
+	delay time.Duration
+}
+
+func (b *slowBus) Write(buf []byte) (int, error) {
+	time.Sleep(b.delay)
+	return b.fakeBus.Write(buf)
+}
+
+func TestOpTimeout(t *testing.T) {
+	bus := &slowBus{delay: time.Millisecond}
+	hd, err := NewHd44780(bus, PCF8574PinMap, RowAddress16Col, SkipInit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hd.SetOpTimeout(30 * time.Millisecond)
+
+	// the deadline is for the whole string rather than each write, a single character fits in it
+	err = hd.WriteChar('a')
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus.written = nil
+	err = hd.DisplayString("a string that takes far longer than the timeout", 0, 0)
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("got %v, want ErrTimeout", err)
+	}
+	// the write in progress at the deadline may carry on in the background
+	for hd.checkStuck() != nil {
+		time.Sleep(bus.delay)
+	}
+	if len(bus.written) == 0 || len(bus.written) >= 40*6 {
+		t.Errorf("got %d bytes written, want the string abandoned part way through", len(bus.written))
+	}
+	if !hd.NeedsResync() {
+		t.Error("NeedsResync is false after a timeout")
+	}
+
+	err = hd.Resync()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hd.NeedsResync() {
+		t.Error("NeedsResync is true after Resync")
+	}
+}
+
+// blockBus is a fakeBus where writes block while block is set, until it's closed.
+type blockBus struct {
+	fakeBus
+	block chan struct{}
+}
+
+func (b *blockBus) Write(buf []byte) (int, error) {
+	if b.block != nil {
+		<-b.block
+	}
+	return b.fakeBus.Write(buf)
+}
+
+func TestOpTimeoutBlockedWrite(t *testing.T) {
+	bus := &blockBus{}
+	hd, err := NewHd44780(bus, PCF8574PinMap, RowAddress16Col, SkipInit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hd.SetOpTimeout(20 * time.Millisecond)
+
+	bus.block = make(chan struct{})
+	start := time.Now()
+	err = hd.WriteChar('a')
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("got %v from a write that blocks, want ErrTimeout", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("took %v to give up on the write, want about the 20ms timeout", d)
+	}
+	if !hd.NeedsResync() {
+		t.Error("NeedsResync is false after a timeout")
+	}
+
+	// nothing can be sent until the write that's stuck returns
+	err = hd.Resync()
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("got %v from Resync while a write is stuck, want ErrTimeout", err)
+	}
+
+	close(bus.block)
+	for i := 0; i < 100; i++ {
+		err = hd.Resync()
+		if err == nil {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("got %v from Resync after the stuck write returned", err)
+	}
+	if hd.NeedsResync() {
+		t.Error("NeedsResync is true after Resync")
+	}
+}
+
+// wordBus is a fakeBus for a 16-bit port expander, every write must set both ports.
+type wordBus struct {
+	fakeBus
+	t *testing.T
+}
+
+func (b *wordBus) Write(buf []byte) (int, error) {
+	if len(buf) != 2 {
+		b.t.Errorf("got a %d byte write, want 2 bytes (both ports) for each write", len(buf))
+	}
+	return b.fakeBus.Write(buf)
+}
+
+// instructions8 decodes the 8-bit mode writes into the bytes the controller received, a byte is read each time EN
+// goes high.
+func (b *wordBus) instructions8(pm I2CPinMap) []instruction {
+	var ins []instruction
+	var last uint16
+	for i := 0; i+1 < len(b.written); i += 2 {
+		w := uint16(b.written[i]) | uint16(b.written[i+1])<<8
+		if w&(0x01<<pm.EN) > 0 && last&(0x01<<pm.EN) == 0 {
+			var data byte
+			for bit, pin := range []byte{pm.D0, pm.D1, pm.D2, pm.D3, pm.D4, pm.D5, pm.D6, pm.D7} {
+				data |= byte((w>>pin)&0x01) << bit
+			}
+			ins = append(ins, instruction{RegisterSelect((w >> pm.RS) & 0x01), data})
+		}
+		last = w
+	}
+	return ins
+}
+
+func TestEightBitMode(t *testing.T) {
+	// D0 - D3 on the first port and D4 - D7 on the second
+	pm := I2CPinMap{
+		RS: 0, RW: 1, EN: 2, Backlight: 3, BLPolarity: Positive,
+		D0: 4, D1: 5, D2: 6, D3: 7,
+		D4: 8, D5: 9, D6: 10, D7: 11,
+	}
+	bus := &wordBus{t: t}
+	hd, err := NewHd44780(bus, pm, RowAddress16Col, EightBitMode)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the init byte is sent whole and there's no switch to 4-bit mode
+	want := []instruction{
+		{registerSelectLow, 0x30},
+		{registerSelectLow, 0x30},
+		{registerSelectLow, 0x30},
+		{registerSelectLow, lcdClearDisplay},
+		{registerSelectLow, 0x06},
+		{registerSelectLow, 0x0c},
+		{registerSelectLow, byte(lcdSetFunctionMode | lcd8BitMode | lcd2Line)},
+	}
+	got := bus.instructions8(pm)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got init instructions %#v, want %#v", got, want)
+	}
+
+	bus.written = nil
+	err = hd.WriteChar(0xa5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the data pins and RS with the backlight on, EN raised then lowered, low port first
+	wantWords := []byte{0x59, 0x0a, 0x5d, 0x0a, 0x59, 0x0a}
+	if !bytes.Equal(bus.written, wantWords) {
+		t.Errorf("got %#v written for 0xa5, want %#v", bus.written, wantWords)
+	}
+}
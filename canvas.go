@@ -0,0 +1,115 @@
+package hd44780
+
+import "fmt"
+
+// Canvas is a monochrome drawing area covering a block of characters, each character is a 5x8 pixel custom
+// character. The whole canvas can be drawn on but only 8 custom characters (4 in 5x10-pixel character mode) can be
+// loaded at once, so each frame can have at most that many different non-blank characters. Render loads the custom
+// characters each frame needs, keeping the ones that are already loaded, so a canvas can be larger than 8
+// characters as long as most of it is blank or repeated, eg a chart.
+//
+// The canvas uses the custom character slots for itself, don't use them for anything else while it's displayed.
+type Canvas struct {
+	hd                       *Hd44780I2c
+	line, col, width, height byte
+	// cells is the canvas drawn as characters, row by row
+	cells []CustomChar
+	// slots is what's loaded in each custom character slot, loaded says whether it's been loaded
+	slots  [8]CustomChar
+	loaded [8]bool
+}
+
+// NewCanvas returns a Canvas width characters wide and height characters high with its top left at col on line,
+// it's width*5 by height*8 pixels.
+func (hd *Hd44780I2c) NewCanvas(line, col, width, height byte) *Canvas {
+	return &Canvas{
+		hd:     hd,
+		line:   line,
+		col:    col,
+		width:  width,
+		height: height,
+		cells:  make([]CustomChar, int(width)*int(height)),
+	}
+}
+
+// SetPixel turns the pixel at x, y on or off, 0, 0 is the top left. Pixels outside of the canvas are ignored.
+// Nothing changes on the display until Render is called.
+func (c *Canvas) SetPixel(x, y int, on bool) {
+	if x < 0 || y < 0 || x >= int(c.width)*5 || y >= int(c.height)*8 {
+		return
+	}
+	cell := &c.cells[y/8*int(c.width)+x/5]
+	bit := byte(0x01) << (4 - x%5)
+	if on {
+		cell[y%8] |= bit
+	} else {
+		cell[y%8] &^= bit
+	}
+}
+
+// Clear turns every pixel off.
+func (c *Canvas) Clear() {
+	for i := range c.cells {
+		c.cells[i] = CustomChar{}
+	}
+}
+
+// Render draws the canvas on the display. Blank characters are shown as spaces so they don't need a custom
+// character, ErrTooManyCustomChars is returned without changing the display if the rest need more custom characters
+// than there are slots.
+func (c *Canvas) Render() error {
+	maxSlots := 8
+	if c.hd.Dots5x10Enabled() {
+		maxSlots = 4
+	}
+
+	// the characters needed for this frame, keeping the slots that already have them loaded
+	needed := make(map[CustomChar]int)
+	for _, cell := range c.cells {
+		if cell == (CustomChar{}) {
+			continue
+		}
+		needed[cell] = -1
+		if len(needed) > maxSlots {
+			return fmt.Errorf("%w: canvas has more than %d different characters", ErrTooManyCustomChars, maxSlots)
+		}
+	}
+	var free []int
+	for slot := 0; slot < maxSlots; slot++ {
+		if _, ok := needed[c.slots[slot]]; ok && c.loaded[slot] && needed[c.slots[slot]] < 0 {
+			needed[c.slots[slot]] = slot
+			continue
+		}
+		free = append(free, slot)
+	}
+
+	// the new characters are loaded in the order they appear
+	for _, cell := range c.cells {
+		if cell == (CustomChar{}) || needed[cell] >= 0 {
+			continue
+		}
+		var slot int
+		slot, free = free[0], free[1:]
+		err := c.hd.SetCustomChar(byte(slot), cell)
+		if err != nil {
+			return err
+		}
+		c.slots[slot], c.loaded[slot] = cell, true
+		needed[cell] = slot
+	}
+
+	row := make([]byte, c.width)
+	for r := 0; r < int(c.height); r++ {
+		for i, cell := range c.cells[r*int(c.width) : (r+1)*int(c.width)] {
+			row[i] = ' '
+			if cell != (CustomChar{}) {
+				row[i] = c.hd.customCharCode(byte(needed[cell]))
+			}
+		}
+		err := c.hd.DisplayBytes(row, c.line+byte(r), c.col)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
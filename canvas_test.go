@@ -0,0 +1,55 @@
+package hd44780
+
+import (
+	"errors"
+	"testing"
+)
+
+// countCGRAMLoads returns the number of custom characters written, each starts with a CGRAM address set.
+func countCGRAMLoads(ins []instruction) int {
+	var n int
+	for _, i := range ins {
+		if i.rs == registerSelectLow && i.data&0xc0 == lcdSetCGRamAddr {
+			n++
+		}
+	}
+	return n
+}
+
+func TestCanvasRender(t *testing.T) {
+	hd, bus := newTestDisplay(t)
+	c := hd.NewCanvas(0, 0, 16, 2)
+
+	// a different character in each of the first 8 cells, the rest blank
+	for i := 0; i < 8; i++ {
+		c.SetPixel(i*5, i, true)
+	}
+	err := c.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := countCGRAMLoads(bus.instructions(hd.PinMap)); n != 8 {
+		t.Errorf("got %d custom characters loaded for the first frame, want 8", n)
+	}
+
+	// moving a pixel into a blank cell only needs the character that changed loading
+	bus.written = nil
+	c.SetPixel(0, 0, false)
+	c.SetPixel(52, 9, true)
+	err = c.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := countCGRAMLoads(bus.instructions(hd.PinMap)); n != 1 {
+		t.Errorf("got %d custom characters loaded for the second frame, want 1", n)
+	}
+	if hd.ddram[0][0x40+10] != 0 {
+		t.Errorf("got %#02x for the cell that changed, want the freed slot 0", hd.ddram[0][0x40+10])
+	}
+
+	c.SetPixel(0, 0, true)
+	err = c.Render()
+	if !errors.Is(err, ErrTooManyCustomChars) {
+		t.Errorf("got %v with 9 different characters, want %v", err, ErrTooManyCustomChars)
+	}
+}
@@ -0,0 +1,165 @@
+package hd44780
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// Special characters in the A00 character ROM, they can be written with WriteGlyph or DisplayBytes.
+const (
+	YenSign    byte = 0x5c
+	RightArrow byte = 0x7e
+	LeftArrow  byte = 0x7f
+	MiddleDot  byte = 0xa5
+	DegreeSign byte = 0xdf
+	Alpha      byte = 0xe0
+	Beta       byte = 0xe2
+	Epsilon    byte = 0xe3
+	Micro      byte = 0xe4
+	Sigma      byte = 0xe5
+	Rho        byte = 0xe6
+	SquareRoot byte = 0xe8
+	CentSign   byte = 0xec
+	Theta      byte = 0xf2
+	Infinity   byte = 0xf3
+	Omega      byte = 0xf4
+	Summation  byte = 0xf6
+	Pi         byte = 0xf7
+	Divide     byte = 0xfd
+	FullBlock  byte = 0xff
+)
+
+// a00Symbols maps runes outside of ASCII to the closest character in the A00 (Japanese standard font) character ROM,
+// the most common ROM.
+var a00Symbols = map[rune]byte{
+	'¥': 0x5c,
+	'→': 0x7e,
+	'←': 0x7f,
+	'・': 0xa5,
+	'•': 0xa5,
+	'·': 0xa5,
+	'°': 0xdf,
+	'º': 0xdf,
+	'α': 0xe0,
+	'ä': 0xe1,
+	'β': 0xe2,
+	'ß': 0xe2,
+	'ε': 0xe3,
+	'μ': 0xe4,
+	'µ': 0xe4,
+	'σ': 0xe5,
+	'ρ': 0xe6,
+	'√': 0xe8,
+	'¢': 0xec,
+	'ñ': 0xee,
+	'ö': 0xef,
+	'θ': 0xf2,
+	'∞': 0xf3,
+	'Ω': 0xf4,
+	'ü': 0xf5,
+	'Σ': 0xf6,
+	'π': 0xf7,
+	'÷': 0xfd,
+	'█': 0xff,
+}
+
+// a00Letters maps accented letters that aren't in the A00 ROM to the unaccented letter.
+var a00Letters = map[rune]byte{
+	'à': 'a', 'á': 'a', 'â': 'a', 'ã': 'a', 'å': 'a',
+	'À': 'A', 'Á': 'A', 'Â': 'A', 'Ã': 'A', 'Ä': 'A', 'Å': 'A',
+	'ç': 'c', 'Ç': 'C',
+	'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e',
+	'È': 'E', 'É': 'E', 'Ê': 'E', 'Ë': 'E',
+	'ì': 'i', 'í': 'i', 'î': 'i', 'ï': 'i',
+	'Ì': 'I', 'Í': 'I', 'Î': 'I', 'Ï': 'I',
+	'Ñ': 'N',
+	'ò': 'o', 'ó': 'o', 'ô': 'o', 'õ': 'o', 'ø': 'o',
+	'Ò': 'O', 'Ó': 'O', 'Ô': 'O', 'Õ': 'O', 'Ö': 'O', 'Ø': 'O',
+	'ù': 'u', 'ú': 'u', 'û': 'u',
+	'Ù': 'U', 'Ú': 'U', 'Û': 'U', 'Ü': 'U',
+	'ý': 'y', 'ÿ': 'y', 'Ý': 'Y',
+}
+
+// transliterate returns the character code for r in the A00 ROM, ok is false if there's nothing close and '?' is
+// returned instead. ASCII maps to itself apart from '\' and '~' which the ROM replaces with '¥' and '→'.
+func transliterate(r rune) (code byte, ok bool) {
+	if r < unicode.MaxASCII && r >= ' ' && r != '\\' && r != '~' {
+		return byte(r), true
+	}
+	if b, ok := a00Symbols[r]; ok {
+		return b, true
+	}
+	if b, ok := a00Letters[r]; ok {
+		return b, true
+	}
+	return '?', false
+}
+
+// charCode returns the character code that r is written as. Without transliteration it's the low byte of r, which
+// is only right for ASCII (and custom characters 0 - 7).
+func (hd *Hd44780I2c) charCode(r rune) byte {
+	if hd.transliterate && r > 7 {
+		code, _ := transliterate(r)
+		return code
+	}
+	return byte(r)
+}
+
+// CanDisplay reports whether r is shown as itself (or a letter without its accent) rather than as some other
+// character, taking into account whether TransliterateOn is set. Codes 0 - 7 are custom characters so they can
+// always be shown.
+func (hd *Hd44780I2c) CanDisplay(r rune) bool {
+	if r >= 0 && r <= 7 {
+		return true
+	}
+	code, ok := transliterate(r)
+	return ok && code == hd.charCode(r)
+}
+
+// Displayable returns the runes in s that CanDisplay says can't be shown, each is only included once. It's empty if
+// the whole of s can be shown.
+func (hd *Hd44780I2c) Displayable(s string) (bad []rune) {
+	seen := make(map[rune]bool)
+	for _, r := range s {
+		if !seen[r] && !hd.CanDisplay(r) {
+			bad = append(bad, r)
+		}
+		seen[r] = true
+	}
+	return bad
+}
+
+// WriteRunes displays runes at the specified position, line and pos are zero indexed. Each rune is converted to the
+// A00 character ROM as it is with TransliterateOn (whether or not it's set) apart from 0 - 7 which are custom
+// characters. Rather than substituting runes that can't be shown ErrUnsupportedRune is returned, before anything is
+// written.
+func (hd *Hd44780I2c) WriteRunes(rs []rune, line, pos byte) error {
+	codes := make([]byte, len(rs))
+	for i, r := range rs {
+		if r >= 0 && r <= 7 {
+			codes[i] = byte(r)
+			continue
+		}
+		code, ok := transliterate(r)
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrUnsupportedRune, r)
+		}
+		codes[i] = code
+	}
+	return hd.DisplayBytes(codes, line, pos)
+}
+
+// WriteGlyph writes a single character code at pos on line, eg DegreeSign. The code is written as it is so it can
+// also be a custom character.
+func (hd *Hd44780I2c) WriteGlyph(code byte, line, pos byte) error {
+	return hd.DisplayBytes([]byte{code}, line, pos)
+}
+
+// TransliterateOn is a ModeSetter that makes strings be converted to the A00 character ROM (the most common) as
+// they're displayed, eg '°' is shown as the ROM's degree sign rather than whatever its low byte happens to be.
+// Letters with accents that aren't in the ROM are shown without the accent and anything else that can't be shown is
+// replaced with '?'. Codes 0 - 7 are left as they are so custom characters can still be used.
+func TransliterateOn(hd *Hd44780I2c) { hd.transliterate = true }
+
+// TransliterateOff is a ModeSetter that turns off transliteration, each rune is displayed as its low byte.
+func TransliterateOff(hd *Hd44780I2c) { hd.transliterate = false }
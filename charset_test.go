@@ -0,0 +1,97 @@
+package hd44780
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTransliterate(t *testing.T) {
+	tests := []struct {
+		r    rune
+		code byte
+		ok   bool
+	}{
+		{'a', 'a', true},
+		{'°', DegreeSign, true},
+		{'→', RightArrow, true},
+		{'•', MiddleDot, true},
+		{'é', 'e', true},
+		{'Ö', 'O', true},
+		{'ö', 0xef, true},
+		{'~', '?', false},
+		{'€', '?', false},
+	}
+	for _, tt := range tests {
+		code, ok := transliterate(tt.r)
+		if code != tt.code || ok != tt.ok {
+			t.Errorf("%q: got %#02x %v, want %#02x %v", tt.r, code, ok, tt.code, tt.ok)
+		}
+	}
+}
+
+func TestDisplayStringTransliterated(t *testing.T) {
+	hd, _ := newTestDisplay(t, TransliterateOn)
+	err := hd.DisplayString("21°C\x01€", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(hd.ddram[0][0x00:0x06]), "21\xdfC\x01?"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// without transliteration it's the low byte
+	err = hd.SetMode(TransliterateOff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = hd.DisplayString("°", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hd.ddram[0][0x00] != 0xb0 {
+		t.Errorf("got %#02x without transliteration, want 0xb0", hd.ddram[0][0x00])
+	}
+}
+
+func TestWriteRunes(t *testing.T) {
+	hd, bus := newTestDisplay(t)
+	err := hd.WriteRunes([]rune("→5µs\x02"), 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(hd.ddram[0][0x43:0x48]), "\x7e5\xe4s\x02"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	bus.written = nil
+	err = hd.WriteRunes([]rune("ok€"), 0, 0)
+	if !errors.Is(err, ErrUnsupportedRune) {
+		t.Errorf("got %v, want ErrUnsupportedRune", err)
+	}
+	if len(bus.written) > 0 {
+		t.Errorf("got %d writes with a rune that can't be shown, want nothing sent", len(bus.written))
+	}
+}
+
+func TestDisplayable(t *testing.T) {
+	hd, _ := newTestDisplay(t)
+
+	// without transliteration only ASCII that the ROM doesn't replace is shown as itself
+	if got := hd.Displayable("~25°C~\x03"); !reflect.DeepEqual(got, []rune{'~', '°'}) {
+		t.Errorf("got %q, want %q", got, "~°")
+	}
+
+	err := hd.SetMode(TransliterateOn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hd.Displayable("25°C café"); len(got) > 0 {
+		t.Errorf("got %q with transliteration, want nothing", got)
+	}
+	for r, want := range map[rune]bool{'°': true, '€': false, '\\': false, 0x07: true} {
+		if hd.CanDisplay(r) != want {
+			t.Errorf("CanDisplay(%q): got %v, want %v", r, !want, want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package hd44780
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Clock shows the time at a fixed position, only the characters that change are rewritten each update so the rest
+// doesn't flicker.
+type Clock struct {
+	hd        *Hd44780I2c
+	line, col byte
+	format    string
+	now       func() time.Time
+	last      []rune
+}
+
+// NewClock returns a Clock that shows the time now returns (time.Now if it's nil) at col on line, formatted with
+// format as by time.Time.Format, eg "15:04:05".
+func (hd *Hd44780I2c) NewClock(line, col byte, format string, now func() time.Time) *Clock {
+	if now == nil {
+		now = time.Now
+	}
+	return &Clock{hd: hd, line: line, col: col, format: format, now: now}
+}
+
+// Update shows the current time, the first update writes all of it and after that only the characters that are
+// different from the last update are written. If the time is shorter than the last one, eg with a month name, it's
+// padded with spaces to clear the rest. The display is written to in a Transaction.
+func (c *Clock) Update() error {
+	next := []rune(c.now().Format(c.format))
+	if len(next) < len(c.last) {
+		next = append(next, []rune(strings.Repeat(" ", len(c.last)-len(next)))...)
+	}
+
+	err := c.hd.Transaction(func(tx *Tx) error {
+		for _, run := range changedRuns(c.last, next) {
+			err := tx.DisplayString(string(next[run[0]:run[1]]), c.line, c.col+byte(run[0]))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		// write all of it next time as it's not known what was written
+		c.last = nil
+		return err
+	}
+	c.last = next
+	return nil
+}
+
+// Invalidate makes the next Update write all of the time, use it after the display has been cleared or
+// overwritten.
+func (c *Clock) Invalidate() {
+	c.last = nil
+}
+
+// Run updates the clock every interval, the first update is immediate. It blocks until ctx is cancelled, in which
+// case it returns nil, or until an update returns an error, which is returned as is.
+func (c *Clock) Run(interval time.Duration, ctx context.Context) error {
+	return Animate([]func() error{c.Update}, interval, ctx)
+}
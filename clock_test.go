@@ -0,0 +1,44 @@
+package hd44780
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClockUpdate(t *testing.T) {
+	hd, bus := newTestDisplay(t)
+	now := time.Date(2020, 1, 1, 12, 59, 58, 0, time.UTC)
+	clock := hd.NewClock(1, 4, "15:04:05", func() time.Time { return now })
+
+	err := clock.Update()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(hd.ddram[0][0x44:0x4c]); got != "12:59:58" {
+		t.Fatalf("got %q displayed, want %q", got, "12:59:58")
+	}
+	bus.written = nil
+
+	// 12:59:58 to 13:00:00 changes the 2 and the last 5 characters but not the 1 or the colons
+	now = now.Add(2 * time.Second)
+	err = clock.Update()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []instruction{
+		{registerSelectLow, lcdSetDDRamAddr | 0x45},
+		{registerSelectHigh, '3'},
+		{registerSelectLow, lcdSetDDRamAddr | 0x47},
+		{registerSelectHigh, '0'},
+		{registerSelectHigh, '0'},
+		{registerSelectLow, lcdSetDDRamAddr | 0x4a},
+		{registerSelectHigh, '0'},
+		{registerSelectHigh, '0'},
+	}
+	got := bus.instructions(hd.PinMap)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got instructions %#v, want %#v", got, want)
+	}
+}
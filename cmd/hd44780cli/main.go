@@ -0,0 +1,82 @@
+// Command hd44780cli shows a message on an HD44780 display connected by an I²C backpack, it's for checking the
+// wiring and the settings of a display without writing any code.
+//
+//	hd44780cli -addr 0x27 -bus 1 -pinmap pcf8574 "hello" "world"
+//
+// Each argument after the flags is shown on its own line. The backlight is flashed off and on first, the message is
+// left on the display when it exits.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/d2r2/go-i2c"
+	"github.com/j0hnsmith/hd44780"
+)
+
+var rowAddresses = map[int]hd44780.RowAddress{
+	16: hd44780.RowAddress16Col,
+	20: hd44780.RowAddress20Col,
+}
+
+func main() {
+	addr := flag.String("addr", "0x27", "I²C address of the backpack, i2cdetect shows it")
+	bus := flag.Int("bus", 1, "I²C bus number, 1 for /dev/i2c-1")
+	pinMap := flag.String("pinmap", "pcf8574", "pin map of the backpack, eg pcf8574 or mjkdz (see hd44780.PinMapByName)")
+	cols := flag.Int("cols", 16, "columns on the display, 16 or 20")
+	rows := flag.Int("rows", 2, "rows on the display, 1 - 4")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] line...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	address, err := strconv.ParseUint(*addr, 0, 8)
+	if err != nil {
+		log.Fatalf("invalid address %q: %v", *addr, err)
+	}
+	pm, ok := hd44780.PinMapByName(*pinMap)
+	if !ok {
+		log.Fatalf("unknown pin map %q", *pinMap)
+	}
+	rowAddr, ok := rowAddresses[*cols]
+	if !ok {
+		log.Fatalf("unsupported number of columns %d", *cols)
+	}
+	if *rows < 1 || *rows > 4 {
+		log.Fatalf("unsupported number of rows %d", *rows)
+	}
+
+	conn, err := i2c.NewI2C(uint8(address), *bus)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer conn.Close()
+
+	lcd, err := hd44780.NewHd44780I2c(conn, pm, rowAddr, hd44780.Dimensions(byte(*rows), byte(*cols)))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = lcd.BacklightOff()
+	if err != nil {
+		log.Fatal(err)
+	}
+	time.Sleep(time.Second / 2)
+	err = lcd.BacklightOn()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for i, line := range flag.Args() {
+		err = lcd.DisplayString(line, byte(i), 0)
+		if err != nil {
+			log.Fatalf("line %d: %v", i, err)
+		}
+	}
+}
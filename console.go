@@ -0,0 +1,49 @@
+package hd44780
+
+// Console uses the display like a scrolling terminal, each line printed goes on the bottom line and the lines
+// above move up.
+type Console struct {
+	hd *Hd44780I2c
+	// Wrap makes lines that are longer than the display is wide carry on onto the next line, otherwise they're
+	// truncated.
+	Wrap bool
+	// lines is a ring buffer of the lines on the display, start is the index of the top line
+	lines []string
+	start int
+}
+
+// NewConsole returns a Console that prints to hd, it uses all of the rows of the display.
+func NewConsole(hd *Hd44780I2c) *Console {
+	return &Console{hd: hd, lines: make([]string, hd.rows)}
+}
+
+// Println prints text on the bottom line, scrolling everything else up.
+func (c *Console) Println(text string) error {
+	width := int(c.hd.cols)
+	r := []rune(text)
+	for {
+		n := len(r)
+		if n > width {
+			n = width
+		}
+		c.lines[c.start] = string(r[:n])
+		c.start = (c.start + 1) % len(c.lines)
+		r = r[n:]
+		if !c.Wrap || len(r) == 0 {
+			break
+		}
+	}
+	return c.render()
+}
+
+// render writes every line, padded so that the previous contents are overwritten.
+func (c *Console) render() error {
+	for i := range c.lines {
+		line := c.lines[(c.start+i)%len(c.lines)]
+		err := c.hd.DisplayString(fit(line, int(c.hd.cols)), byte(i), 0)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package hd44780
+
+import (
+	"reflect"
+	"testing"
+)
+
+// rows returns the text on each row of the display from hd.ddram.
+func rows(hd *Hd44780I2c) []string {
+	var rows []string
+	for r := 0; r < int(hd.rows); r++ {
+		start := int(hd.RowAddr[r])
+		rows = append(rows, string(hd.ddram[hd.rowController(byte(r))][start:start+int(hd.cols)]))
+	}
+	return rows
+}
+
+func TestConsolePrintln(t *testing.T) {
+	hd, _ := newTestDisplay(t)
+	c := NewConsole(hd)
+
+	for _, text := range []string{"one", "two", "three"} {
+		err := c.Println(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{"two             ", "three           "}
+	if got := rows(hd); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	err := c.Println("a line that's too long")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"three           ", "a line that's to"}
+	if got := rows(hd); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q truncated, want %q", got, want)
+	}
+
+	c.Wrap = true
+	err = c.Println("a line that's too long")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"a line that's to", "o long          "}
+	if got := rows(hd); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q wrapped, want %q", got, want)
+	}
+}
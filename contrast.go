@@ -0,0 +1,21 @@
+package hd44780
+
+// ContrastController sets the contrast of a display, the HD44780 has no contrast instruction so it's for backpacks
+// that have a DAC or PWM output (usually on a separate chip) driving the contrast pin.
+type ContrastController interface {
+	SetContrast(level uint8) error
+}
+
+// ContrastControl returns a ModeSetter that makes SetContrast use c.
+func ContrastControl(c ContrastController) ModeSetter {
+	return func(hd *Hd44780I2c) { hd.contrast = c }
+}
+
+// SetContrast sets the contrast with the ContrastController set with ContrastControl, what the levels mean is up
+// to the controller. ErrUnsupported is returned if there isn't one.
+func (hd *Hd44780I2c) SetContrast(level uint8) error {
+	if hd.contrast == nil {
+		return ErrUnsupported
+	}
+	return hd.contrast.SetContrast(level)
+}
@@ -0,0 +1,186 @@
+package hd44780
+
+// SetCursor moves the cursor to the given row and column, both are zero indexed.
+// ErrInvalidLine or ErrInvalidPos is returned if the position isn't on the display.
+func (hd *Hd44780I2c) SetCursor(row, col byte) error {
+	address, ctrl, err := hd.address(row, col)
+	if err != nil {
+		return err
+	}
+	hd.ctrl = ctrl
+	err = hd.WriteInstruction(lcdSetDDRamAddr | address)
+	if err != nil {
+		return err
+	}
+	hd.curRow, hd.curCol = row, col
+	return nil
+}
+
+// Cursor returns the row and column that the next character will be written to. The display can't be read so the
+// position is tracked in software as characters are written, it's only correct if all instructions are sent using
+// the methods of Hd44780I2c rather than WriteInstruction. Like the display's own address counter, the column isn't
+// wrapped onto the next line so it can be beyond the last column (or wrap around to 255 in entry decrement mode).
+func (hd *Hd44780I2c) Cursor() (row, col byte) {
+	return hd.curRow, hd.curCol
+}
+
+// cursorAddress returns the DDRAM address of the tracked cursor position.
+func (hd *Hd44780I2c) cursorAddress() byte {
+	return hd.cellAddress(hd.curRow, hd.curCol)
+}
+
+// rowController returns the controller that drives row, with DualController the first 2 rows are on the first
+// controller and the last 2 on the second.
+func (hd *Hd44780I2c) rowController(row byte) byte {
+	if hd.dual {
+		return row / 2
+	}
+	return 0
+}
+
+// controllerEnableBit returns the EN pin of the controller the cursor is on.
+func (hd *Hd44780I2c) controllerEnableBit() uint16 {
+	if hd.dual && hd.ctrl == 1 {
+		return 0x01 << hd.en2
+	}
+	return 0x01 << hd.PinMap.EN
+}
+
+// allEnableBits returns the EN pins of all of the controllers.
+func (hd *Hd44780I2c) allEnableBits() uint16 {
+	if hd.dual {
+		return 0x01<<hd.PinMap.EN | 0x01<<hd.en2
+	}
+	return 0x01 << hd.PinMap.EN
+}
+
+// enableBits returns the EN pins to pulse to write data with rs, characters and DDRAM addresses go to the
+// controller the cursor is on and everything else goes to all of them.
+func (hd *Hd44780I2c) enableBits(data byte, rs RegisterSelect) uint16 {
+	if (rs == registerSelectHigh && !hd.inCGRAM) || (rs == registerSelectLow && data&lcdSetDDRamAddr > 0) {
+		return hd.controllerEnableBit()
+	}
+	return hd.allEnableBits()
+}
+
+// cellAddress returns the DDRAM address of the cell at row and col. With Split16x1 the right half of the line is
+// in the second line of DDRAM.
+func (hd *Hd44780I2c) cellAddress(row, col byte) byte {
+	if hd.splitAt > 0 && col >= hd.splitAt {
+		return hd.RowAddr[row] + 0x40 + col - hd.splitAt
+	}
+	return hd.RowAddr[row] + col
+}
+
+// crossesSplit returns true if the next character written is on the other side of the split from the last one with
+// Split16x1, the address counter doesn't go from one half to the other by itself.
+func (hd *Hd44780I2c) crossesSplit() bool {
+	if hd.splitAt == 0 || hd.inCGRAM {
+		return false
+	}
+	if hd.EntryIncrementEnabled() {
+		return hd.curCol == hd.splitAt
+	}
+	return hd.curCol == hd.splitAt-1
+}
+
+// advanceCursor moves the tracked cursor position on by one character in the direction of the entry mode.
+func (hd *Hd44780I2c) advanceCursor() {
+	if hd.EntryIncrementEnabled() {
+		hd.curCol++
+	} else {
+		hd.curCol--
+	}
+}
+
+// setCursorFromAddress sets the tracked cursor position from a DDRAM address, the row is the one with the closest
+// start address before it. With DualController only the rows of the controller the cursor is on are looked at, as
+// both controllers have the same addresses.
+func (hd *Hd44780I2c) setCursorFromAddress(address byte) {
+	if right := hd.RowAddr[0] + 0x40; hd.splitAt > 0 && address >= right {
+		hd.curRow, hd.curCol = 0, hd.splitAt+address-right
+		return
+	}
+	first, last := 0, int(hd.rows)
+	if hd.dual {
+		first = int(hd.ctrl) * 2
+		last = first + 2
+	}
+	row := first
+	for r := first + 1; r < last && r < len(hd.RowAddr); r++ {
+		if hd.RowAddr[r] <= address && hd.RowAddr[r] > hd.RowAddr[row] {
+			row = r
+		}
+	}
+	hd.curRow, hd.curCol = byte(row), address-hd.RowAddr[row]
+}
+
+// lineWrap is what WriteChar does when it gets to the end of a line.
+type lineWrap byte
+
+const (
+	wrapOff lineWrap = iota
+	wrapToTop
+	wrapScroll
+)
+
+// LineWrapOff is a ModeSetter that makes characters written past the end of a line go into DDRAM that isn't shown,
+// as the controller does by itself. It's the default.
+func LineWrapOff(hd *Hd44780I2c) { hd.wrap = wrapOff }
+
+// LineWrapToTop is a ModeSetter that makes a character written past the end of a line go at the start of the next
+// line, after the last line it goes back to the top line. It applies to everything that writes characters, eg
+// DisplayString and Write, in entry increment mode.
+func LineWrapToTop(hd *Hd44780I2c) { hd.wrap = wrapToTop }
+
+// LineWrapScroll is a ModeSetter like LineWrapToTop except that after the last line every line is moved up one
+// and the character goes at the start of the blank bottom line, like a terminal. The lines are rewritten from the
+// copy of DDRAM kept in software so anything written with raw instructions is lost.
+func LineWrapScroll(hd *Hd44780I2c) { hd.wrap = wrapScroll }
+
+// wrapLine moves the cursor to the start of the next line, or wraps or scrolls after the last line.
+func (hd *Hd44780I2c) wrapLine() error {
+	if hd.curRow+1 < hd.rows {
+		return hd.SetCursor(hd.curRow+1, 0)
+	}
+	if hd.wrap == wrapToTop {
+		return hd.SetCursor(0, 0)
+	}
+
+	row := make([]byte, hd.cols)
+	for r := byte(0); r < hd.rows; r++ {
+		for c := range row {
+			row[c] = ' '
+			if r+1 < hd.rows {
+				row[c] = hd.cellCode(r+1, byte(c))
+			}
+		}
+		err := hd.DisplayBytes(row, r, 0)
+		if err != nil {
+			return err
+		}
+	}
+	return hd.SetCursor(hd.rows-1, 0)
+}
+
+// ParkCursor moves the cursor out of the way of the content, to the position set with ParkAt or the last cell of
+// the display by default, so that a cursor that's turned on doesn't sit in the middle of the text.
+func (hd *Hd44780I2c) ParkCursor() error {
+	if hd.parkSet {
+		return hd.SetCursor(hd.parkRow, hd.parkCol)
+	}
+	return hd.SetCursor(hd.rows-1, hd.cols-1)
+}
+
+// ParkAt is a ModeSetter that sets where ParkCursor moves the cursor to.
+func ParkAt(row, col byte) ModeSetter {
+	return func(hd *Hd44780I2c) {
+		hd.parkRow, hd.parkCol, hd.parkSet = row, col, true
+	}
+}
+
+// AutoParkOn is a ModeSetter that makes DisplayString park the cursor (see ParkCursor) after writing.
+func AutoParkOn(hd *Hd44780I2c) { hd.autoPark = true }
+
+// AutoParkOff is a ModeSetter that leaves the cursor after the text DisplayString writes, the default.
+func AutoParkOff(hd *Hd44780I2c) { hd.autoPark = false }
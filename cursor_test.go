@@ -0,0 +1,71 @@
+package hd44780
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAdvanceCursor(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      ModeSetter
+		line      byte
+		addresses []byte
+	}{
+		{"increment", EntryIncrement, 0, []byte{0x00, 0x01, 0x02, 0x03, 0x04}},
+		{"increment second line", EntryIncrement, 1, []byte{0x40, 0x41, 0x42, 0x43, 0x44}},
+		{"decrement", EntryDecrement, 0, []byte{0x0f, 0x0e, 0x0d, 0x0c, 0x0b}},
+		{"decrement second line", EntryDecrement, 1, []byte{0x4f, 0x4e, 0x4d, 0x4c, 0x4b}},
+	}
+
+	for _, tt := range tests {
+		hd, bus := newTestDisplay(t, tt.mode)
+		err := hd.DisplayString("text", tt.line, hd.lineStart())
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = hd.WriteChar('!')
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// only the first address is sent, the controller moves on by itself after each char
+		want := []instruction{{registerSelectLow, lcdSetDDRamAddr | tt.addresses[0]}}
+		for _, c := range []byte("text!") {
+			want = append(want, instruction{registerSelectHigh, c})
+		}
+		if got := bus.instructions(hd.PinMap); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got instructions %#v, want %#v", tt.name, got, want)
+		}
+		for i, addr := range tt.addresses {
+			if got := hd.ddram[0][addr]; got != "text!"[i] {
+				t.Errorf("%s: got %q at %#02x, want %q", tt.name, got, addr, "text!"[i])
+			}
+		}
+	}
+}
+
+func TestLineWrap(t *testing.T) {
+	tests := []struct {
+		mode ModeSetter
+		rows [2]string
+	}{
+		{LineWrapToTop, [2]string{"012345abcdefghij", "klmnopqrstuvwxyz"}},
+		{LineWrapScroll, [2]string{"klmnopqrstuvwxyz", "012345          "}},
+	}
+
+	for _, tt := range tests {
+		hd, _ := newTestDisplay(t, tt.mode)
+
+		err := hd.DisplayString("abcdefghijklmnopqrstuvwxyz012345", 0, 6)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for r, want := range tt.rows {
+			got := string(hd.ddram[0][hd.RowAddr[r] : hd.RowAddr[r]+16])
+			if got != want {
+				t.Errorf("row %d: got %q, want %q", r, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package hd44780
+
+import "fmt"
+
+// NewCustomCharSet returns a set of custom characters for LoadCustomChars, chars are put in slots from 0 and any
+// slots that are left are blank. ErrTooManyCustomChars is returned if more than 8 chars are given.
+func NewCustomCharSet(chars ...CustomChar) ([8]CustomChar, error) {
+	var set [8]CustomChar
+	if len(chars) > len(set) {
+		return set, fmt.Errorf("%w: %d custom chars given, only 8 fit", ErrTooManyCustomChars, len(chars))
+	}
+	copy(set[:], chars)
+	return set, nil
+}
+
+// CustomChar5x10 represents the data for a custom character in 5x10-pixel character mode, it's the same as CustomChar
+// but with 2 more lines at the bottom.
+type CustomChar5x10 [10]byte
+
+// SetCustomChar stores a single custom character in CGRAM, slot is 0 - 7. The cursor is put back where it was
+// afterwards. In 5x10-pixel character mode slot is 0 - 3 and the bottom 2 lines of the character are blank.
+func (hd *Hd44780I2c) SetCustomChar(slot byte, c CustomChar) error {
+	defer hd.startOp()()
+
+	if hd.Dots5x10Enabled() {
+		var c10 CustomChar5x10
+		copy(c10[:], c[:])
+		return hd.Set5x10CustomChar(slot, c10)
+	}
+	if slot > 7 {
+		return fmt.Errorf("%w: %d", ErrInvalidSlot, slot)
+	}
+	lines, err := hd.customCharLines(int(slot), c[:])
+	if err != nil {
+		return err
+	}
+
+	err = hd.WriteInstruction(cgramAddr(slot))
+	if err != nil {
+		return err
+	}
+	for line, b := range lines {
+		err = hd.write(b, registerSelectHigh)
+		if err != nil {
+			return hd.customCharError(int(slot), line, err)
+		}
+	}
+	return hd.restoreDDRamAddr()
+}
+
+// Set5x10CustomChar stores a single custom character in CGRAM when in 5x10-pixel character mode, slot is 0 - 3. Each
+// character takes up the space of 2 5x8 characters and the controller ignores bit 0 of the character code, so slot
+// s is shown by codes 2s and 2s+1, eg slot 1 is shown by writing 2 (or 3). The 11th line, where the cursor is
+// shown, is set blank. The cursor is put back where it was afterwards. An error wrapping ErrDotMode is returned in
+// 5x8-pixel character mode.
+func (hd *Hd44780I2c) Set5x10CustomChar(slot byte, c CustomChar5x10) error {
+	defer hd.startOp()()
+
+	if !hd.Dots5x10Enabled() {
+		return fmt.Errorf("%w: 5x10 custom characters need 5x10-pixel character mode", ErrDotMode)
+	}
+	if slot > 3 {
+		return fmt.Errorf("%w: %d", ErrInvalidSlot, slot)
+	}
+	lines, err := hd.customCharLines(int(slot), c[:])
+	if err != nil {
+		return err
+	}
+
+	err = hd.WriteInstruction(lcdSetCGRamAddr | (slot << 4))
+	if err != nil {
+		return err
+	}
+	for line, b := range append(lines, 0x00) {
+		err = hd.write(b, registerSelectHigh)
+		if err != nil {
+			return hd.customCharError(int(slot), line, err)
+		}
+	}
+	return hd.restoreDDRamAddr()
+}
+
+// ClearCustomChar sets every line of the custom character in slot to blank.
+func (hd *Hd44780I2c) ClearCustomChar(slot byte) error {
+	return hd.SetCustomChar(slot, CustomChar{})
+}
+
+// InvertCustomChar reads the custom character in slot and writes it back with every pixel inverted, eg to
+// highlight it. RW must be wired and the bus must implement io.Reader, it's not supported in 5x10-pixel character
+// mode.
+func (hd *Hd44780I2c) InvertCustomChar(slot byte) error {
+	if hd.Dots5x10Enabled() {
+		return fmt.Errorf("%w: InvertCustomChar in 5x10-pixel character mode", ErrUnsupported)
+	}
+	if slot > 7 {
+		return fmt.Errorf("%w: %d", ErrInvalidSlot, slot)
+	}
+
+	err := hd.WriteInstruction(cgramAddr(slot))
+	if err != nil {
+		return err
+	}
+	var c CustomChar
+	for line := range c {
+		b, err := hd.readByte(registerSelectHigh)
+		if err != nil {
+			// the read error is more useful than another one from restoring
+			_ = hd.restoreDDRamAddr()
+			return fmt.Errorf("hd44780: reading custom character %d line %d: %w", slot, line, err)
+		}
+		c[line] = ^b & 0x1f
+	}
+	return hd.SetCustomChar(slot, c)
+}
+
+// cgramAddr returns the instruction that sets the address counter to the first line of a custom character slot in
+// 5x8-pixel character mode, each slot is 8 bytes of CGRAM. The characters are loaded one after the other from slot
+// 0 so LoadCustomChars only needs the address of slot 0.
+func cgramAddr(slot byte) byte {
+	return lcdSetCGRamAddr | slot<<3
+}
+
+// customCharCode returns the character code that shows the custom character in slot, it's the slot in 5x8-pixel
+// character mode but twice the slot in 5x10-pixel character mode, where CGRAM address bits 5 - 4 come from bits 2 - 1
+// of the code.
+func (hd *Hd44780I2c) customCharCode(slot byte) byte {
+	if hd.Dots5x10Enabled() {
+		return slot << 1
+	}
+	return slot
+}
+
+// customCharLines returns the lines of a custom character with only bits 0 - 4 kept, the controller ignores the
+// rest. With StrictCustomChars set ErrInvalidCustomChar is returned instead if any of the other bits are set.
+func (hd *Hd44780I2c) customCharLines(slot int, lines []byte) ([]byte, error) {
+	masked := make([]byte, len(lines))
+	for line, b := range lines {
+		if b&^0x1f != 0 && hd.strictChars {
+			return nil, fmt.Errorf("%w: custom character %d line %d is %#02x", ErrInvalidCustomChar, slot, line, b)
+		}
+		masked[line] = b & 0x1f
+	}
+	return masked, nil
+}
+
+// StrictCustomChars is a ModeSetter that makes setting a custom character with bits set above bit 4 return
+// ErrInvalidCustomChar, rather than ignoring those bits, to catch mistakes in glyph data.
+func StrictCustomChars(hd *Hd44780I2c) { hd.strictChars = true }
+
+// LenientCustomChars is a ModeSetter that makes bits above bit 4 of custom character lines be ignored, the default.
+func LenientCustomChars(hd *Hd44780I2c) { hd.strictChars = false }
+
+// customCharError tries to put the address counter back in DDRAM after a failed CGRAM write, so the display can
+// still be used, and returns err as a *CustomCharError.
+func (hd *Hd44780I2c) customCharError(slot, line int, err error) error {
+	// the write error is more useful than another one from restoring
+	_ = hd.restoreDDRamAddr()
+	return &CustomCharError{Slot: slot, Line: line, Err: err}
+}
+
+// restoreDDRamAddr sets the address counter back to the tracked cursor position, the address counter is left in
+// CGRAM after writing custom characters so without this the next char written would change a custom character.
+func (hd *Hd44780I2c) restoreDDRamAddr() error {
+	return hd.WriteInstruction(lcdSetDDRamAddr | hd.cursorAddress())
+}
+
+// CustomCharFromGrid packs a grid of pixels into a CustomChar, grid[0] is the topmost line and grid[x][0] is the
+// leftmost pixel of a line. It makes a glyph readable in source, eg a bell
+//
+//	x, o := true, false
+//	bell := hd44780.CustomCharFromGrid([8][5]bool{
+//		{o, o, x, o, o},
+//		{o, x, x, x, o},
+//		{o, x, x, x, o},
+//		{o, x, x, x, o},
+//		{x, x, x, x, x},
+//		{o, o, o, o, o},
+//		{o, o, x, o, o},
+//		{o, o, o, o, o},
+//	})
+func CustomCharFromGrid(grid [8][5]bool) CustomChar {
+	var c CustomChar
+	for row, pixels := range grid {
+		for col, on := range pixels {
+			if on {
+				c[row] |= 0x01 << (4 - col)
+			}
+		}
+	}
+	return c
+}
+
+// ToGrid unpacks the CustomChar into a grid of pixels, the inverse of CustomCharFromGrid.
+func (c CustomChar) ToGrid() [8][5]bool {
+	var grid [8][5]bool
+	for row, b := range c {
+		for col := range grid[row] {
+			grid[row][col] = b&(0x01<<(4-col)) > 0
+		}
+	}
+	return grid
+}
@@ -0,0 +1,47 @@
+package hd44780_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/j0hnsmith/hd44780"
+)
+
+func TestCustomCharFromGrid(t *testing.T) {
+	x, o := true, false
+	grid := [8][5]bool{
+		{o, x, x, x, o},
+		{x, x, o, x, x},
+		{x, o, o, o, x},
+		{x, o, o, o, x},
+		{x, o, o, o, x},
+		{x, o, o, o, x},
+		{x, o, o, o, x},
+		{x, x, x, x, x},
+	}
+	want := hd44780.CustomChar{0xe, 0x1b, 0x11, 0x11, 0x11, 0x11, 0x11, 0x1f}
+
+	c := hd44780.CustomCharFromGrid(grid)
+	if c != want {
+		t.Errorf("got %#v, want %#v", c, want)
+	}
+	if c.ToGrid() != grid {
+		t.Errorf("ToGrid didn't return the original grid: %v", c.ToGrid())
+	}
+}
+
+func TestNewCustomCharSet(t *testing.T) {
+	a := hd44780.CustomChar{0x1f}
+	set, err := hd44780.NewCustomCharSet(a, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set[1] != a || set[2] != (hd44780.CustomChar{}) {
+		t.Errorf("got %#v, want a in the first 2 slots and the rest blank", set)
+	}
+
+	_, err = hd44780.NewCustomCharSet(make([]hd44780.CustomChar, 9)...)
+	if !errors.Is(err, hd44780.ErrTooManyCustomChars) {
+		t.Errorf("got %v for 9 custom chars, want ErrTooManyCustomChars", err)
+	}
+}
@@ -0,0 +1,102 @@
+package hd44780
+
+// Display is the main methods of Hd44780I2c, code that writes to a display can accept a Display so that a fake can
+// be used in tests.
+type Display interface {
+	DisplayString(str string, line, pos byte) error
+	WriteChar(value byte) error
+	Clear() error
+	Home() error
+	SetCursor(row, col byte) error
+	BacklightOn() error
+	BacklightOff() error
+	UnderlineCursorOn() error
+	UnderlineCursorOff() error
+	BlinkCursorOn() error
+	BlinkCursorOff() error
+}
+
+var _ Display = (*Hd44780I2c)(nil)
+
+// MultiDisplay is a Display that writes to several displays, eg identical displays at different addresses showing
+// the same thing. Each method is called on every display in turn, even if it fails on one of them.
+type MultiDisplay struct {
+	displays []Display
+}
+
+var _ Display = (*MultiDisplay)(nil)
+
+// NewMultiDisplay returns a MultiDisplay that writes to displays.
+func NewMultiDisplay(displays ...Display) *MultiDisplay {
+	return &MultiDisplay{displays: displays}
+}
+
+// each calls fn with each display, it returns a *MultiDisplayError if any of the calls fail.
+func (m *MultiDisplay) each(fn func(d Display) error) error {
+	var errs []error
+	failed := false
+	for _, d := range m.displays {
+		err := fn(d)
+		errs = append(errs, err)
+		failed = failed || err != nil
+	}
+	if !failed {
+		return nil
+	}
+	return &MultiDisplayError{Errs: errs}
+}
+
+// DisplayString calls DisplayString on each display.
+func (m *MultiDisplay) DisplayString(str string, line, pos byte) error {
+	return m.each(func(d Display) error { return d.DisplayString(str, line, pos) })
+}
+
+// WriteChar calls WriteChar on each display.
+func (m *MultiDisplay) WriteChar(value byte) error {
+	return m.each(func(d Display) error { return d.WriteChar(value) })
+}
+
+// Clear calls Clear on each display.
+func (m *MultiDisplay) Clear() error {
+	return m.each(Display.Clear)
+}
+
+// Home calls Home on each display.
+func (m *MultiDisplay) Home() error {
+	return m.each(Display.Home)
+}
+
+// SetCursor calls SetCursor on each display.
+func (m *MultiDisplay) SetCursor(row, col byte) error {
+	return m.each(func(d Display) error { return d.SetCursor(row, col) })
+}
+
+// BacklightOn calls BacklightOn on each display.
+func (m *MultiDisplay) BacklightOn() error {
+	return m.each(Display.BacklightOn)
+}
+
+// BacklightOff calls BacklightOff on each display.
+func (m *MultiDisplay) BacklightOff() error {
+	return m.each(Display.BacklightOff)
+}
+
+// UnderlineCursorOn calls UnderlineCursorOn on each display.
+func (m *MultiDisplay) UnderlineCursorOn() error {
+	return m.each(Display.UnderlineCursorOn)
+}
+
+// UnderlineCursorOff calls UnderlineCursorOff on each display.
+func (m *MultiDisplay) UnderlineCursorOff() error {
+	return m.each(Display.UnderlineCursorOff)
+}
+
+// BlinkCursorOn calls BlinkCursorOn on each display.
+func (m *MultiDisplay) BlinkCursorOn() error {
+	return m.each(Display.BlinkCursorOn)
+}
+
+// BlinkCursorOff calls BlinkCursorOff on each display.
+func (m *MultiDisplay) BlinkCursorOff() error {
+	return m.each(Display.BlinkCursorOff)
+}
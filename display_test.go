@@ -0,0 +1,33 @@
+package hd44780
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMultiDisplay(t *testing.T) {
+	hd1, _ := newTestDisplay(t)
+	hd2, bus2 := newTestDisplay(t)
+	m := NewMultiDisplay(hd1, hd2)
+
+	err := m.DisplayString("hi", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, hd := range []*Hd44780I2c{hd1, hd2} {
+		if got := string(hd.ddram[0][:2]); got != "hi" {
+			t.Errorf("display %d: got %q, want %q", i, got, "hi")
+		}
+	}
+
+	// the first display is still written to when the second fails
+	bus2.failures = 1
+	err = m.DisplayString("yo", 1, 0)
+	var multiErr *MultiDisplayError
+	if !errors.As(err, &multiErr) || multiErr.Errs[0] != nil || multiErr.Errs[1] == nil {
+		t.Fatalf("got %v, want a MultiDisplayError for the second display", err)
+	}
+	if got := string(hd1.ddram[0][0x40:0x42]); got != "yo" {
+		t.Errorf("got %q on the first display, want %q", got, "yo")
+	}
+}
@@ -0,0 +1,116 @@
+package hd44780
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidLine is returned when a line number isn't one of the rows of the display.
+	ErrInvalidLine = errors.New("hd44780: invalid line")
+	// ErrInvalidPos is returned when a position is beyond the last column of the display.
+	ErrInvalidPos = errors.New("hd44780: invalid position")
+	// ErrInvalidSlot is returned when a custom character slot isn't 0 - 7.
+	ErrInvalidSlot = errors.New("hd44780: invalid custom character slot")
+	// ErrTooManyCustomChars is returned when more custom characters are given than there are slots for.
+	ErrTooManyCustomChars = errors.New("hd44780: too many custom characters")
+	// ErrVerifyFailed is returned when VerifyWrites is set and the data read back doesn't match what was written.
+	ErrVerifyFailed = errors.New("hd44780: verify failed")
+	// ErrTimeout is returned when an operation takes longer than the timeout set with SetOpTimeout, the display
+	// then needs Resync.
+	ErrTimeout = errors.New("hd44780: timeout writing to bus")
+	// ErrUnsupportedRune is returned when a rune can't be shown on the display.
+	ErrUnsupportedRune = errors.New("hd44780: rune can't be displayed")
+	// ErrDotMode is returned when a custom character is set that doesn't fit the pixel character mode, eg a 5x10
+	// custom character in 5x8-pixel character mode.
+	ErrDotMode = errors.New("hd44780: wrong pixel character mode")
+	// ErrInvalidCustomChar is returned when StrictCustomChars is set and a line of a custom character has bits set
+	// above bit 4.
+	ErrInvalidCustomChar = errors.New("hd44780: invalid custom character line")
+	// ErrUnsupported is returned when the display or backpack doesn't have a feature, eg contrast control.
+	ErrUnsupported = errors.New("hd44780: not supported")
+	// ErrInvalidGeometry is returned when the size given to Geometry isn't a display size that's supported.
+	ErrInvalidGeometry = errors.New("hd44780: invalid geometry")
+	// ErrOverflow is returned when StrictOverflow is set and text doesn't fit on the line.
+	ErrOverflow = errors.New("hd44780: text doesn't fit on the line")
+	// ErrOverlap is returned when a field added to a Panel overlaps one that's already in it.
+	ErrOverlap = errors.New("hd44780: fields overlap")
+	// ErrInvalidPinMap is returned when a pin of an I2CPinMap isn't on the port expander or is used twice.
+	ErrInvalidPinMap = errors.New("hd44780: invalid pin map")
+	// ErrBusMode is returned when VerifyBusMode is set and the controller isn't in the bus mode it was set to.
+	ErrBusMode = errors.New("hd44780: wrong bus mode")
+	// ErrCommitWithoutBegin is returned when FrameBuffer.Commit is called without a transaction open.
+	ErrCommitWithoutBegin = errors.New("hd44780: Commit without Begin")
+	// ErrReadNotSupported is returned when reading from the display but the bus doesn't implement io.Reader.
+	ErrReadNotSupported = errors.New("hd44780: bus doesn't support reads")
+
+	// ErrBusUnreachable is the stage of an InitError when the very first write to the bus failed, this usually
+	// means the bus or the address of the port expander is wrong.
+	ErrBusUnreachable = errors.New("hd44780: can't write to bus")
+	// ErrInitFailed is the stage of an InitError when the bus could be written to but a later instruction failed.
+	ErrInitFailed = errors.New("hd44780: init failed")
+	// ErrNotResponding is the stage of an InitError when CheckConnection is set and the display didn't return the
+	// data written to it, usually the wiring or the address is wrong or the display isn't powered.
+	ErrNotResponding = errors.New("hd44780: display not responding")
+)
+
+// CustomCharError is returned when writing a custom character to CGRAM fails part way through, the slot is left
+// partly written (lines before Line have the new data) and should be written again.
+type CustomCharError struct {
+	Slot, Line int
+	Err        error
+}
+
+func (e *CustomCharError) Error() string {
+	return fmt.Sprintf("hd44780: writing custom character %d line %d: %v", e.Slot, e.Line, e.Err)
+}
+
+// Unwrap returns the underlying bus error.
+func (e *CustomCharError) Unwrap() error { return e.Err }
+
+// InitError is returned by the constructors when the display can't be initialised. errors.Is reports whether it
+// matches its Stage, and errors.Unwrap returns the underlying bus error.
+type InitError struct {
+	// Stage is ErrBusUnreachable or ErrInitFailed.
+	Stage error
+	Err   error
+}
+
+func (e *InitError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Stage, e.Err)
+}
+
+// Unwrap returns the underlying bus error.
+func (e *InitError) Unwrap() error { return e.Err }
+
+// Is reports whether target is the stage of the error.
+func (e *InitError) Is(target error) bool { return target == e.Stage }
+
+// MultiDisplayError is returned by the methods of MultiDisplay when they fail on any of the displays.
+type MultiDisplayError struct {
+	// Errs are the errors from each display in the order they were given to NewMultiDisplay, nil for the ones that
+	// didn't fail.
+	Errs []error
+}
+
+func (e *MultiDisplayError) Error() string {
+	var msgs []string
+	for i, err := range e.Errs {
+		if err != nil {
+			msgs = append(msgs, fmt.Sprintf("display %d: %v", i, err))
+		}
+	}
+	return "hd44780: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors of the displays that failed, so errors.Is and errors.As check each of them.
+func (e *MultiDisplayError) Unwrap() []error {
+	var errs []error
+	for _, err := range e.Errs {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
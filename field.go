@@ -0,0 +1,74 @@
+package hd44780
+
+import "strings"
+
+// Field is a fixed region of a line that's updated in place, eg a temperature reading on a dashboard.
+type Field struct {
+	hd               *Hd44780I2c
+	line, col, width byte
+	last             string
+	written          bool
+}
+
+// NewField returns a Field width characters wide starting at col on line.
+func (hd *Hd44780I2c) NewField(line, col, width byte) *Field {
+	return &Field{hd: hd, line: line, col: col, width: width}
+}
+
+// Set displays value in the field, it's padded with spaces or truncated to the width of the field. Nothing is
+// written if value is the same as the last value set.
+func (f *Field) Set(value string) error {
+	return f.set(value, false)
+}
+
+// set is Set, if atCursor is true the cursor is already at the start of the field so the address isn't sent.
+func (f *Field) set(value string, atCursor bool) error {
+	value = fit(value, int(f.width))
+	if f.written && value == f.last {
+		return nil
+	}
+
+	var err error
+	if atCursor {
+		err = f.hd.WriteString(value)
+	} else {
+		err = f.hd.DisplayString(value, f.line, f.col)
+	}
+	if err != nil {
+		return err
+	}
+	f.last, f.written = value, true
+	return nil
+}
+
+// Invalidate makes the next Set write to the display even if the value hasn't changed, use it after the display
+// has been cleared or overwritten.
+func (f *Field) Invalidate() {
+	f.written = false
+}
+
+// fit pads s with spaces or truncates it so it's width characters long.
+func fit(s string, width int) string {
+	r := []rune(s)
+	if len(r) >= width {
+		return string(r[:width])
+	}
+	return s + strings.Repeat(" ", width-len(r))
+}
+
+// changedRuns returns the start and end (exclusive) of each run of runes in next that's different from the rune at
+// the same index in prev, next is assumed to be at least as long as prev.
+func changedRuns(prev, next []rune) [][2]int {
+	var runs [][2]int
+	for i := 0; i < len(next); i++ {
+		if i < len(prev) && next[i] == prev[i] {
+			continue
+		}
+		start := i
+		for i < len(next) && (i >= len(prev) || next[i] != prev[i]) {
+			i++
+		}
+		runs = append(runs, [2]int{start, i})
+	}
+	return runs
+}
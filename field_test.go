@@ -0,0 +1,48 @@
+package hd44780
+
+import "testing"
+
+func TestFieldSet(t *testing.T) {
+	hd, bus := newTestDisplay(t)
+	f := hd.NewField(1, 10, 5)
+
+	err := f.Set("21.5C")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.Set("9C")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(hd.ddram[0][0x4a:0x4f]); got != "9C   " {
+		t.Errorf("got %q displayed, want %q padded to the width", got, "9C   ")
+	}
+	err = f.Set("100.25C")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(hd.ddram[0][0x4a:0x50]); got != "100.2 " {
+		t.Errorf("got %q displayed, want %q truncated to the width", got, "100.2 ")
+	}
+
+	// the same value again, including after it's been truncated, isn't written
+	bus.written = nil
+	for _, v := range []string{"100.2", "100.25C"} {
+		err = f.Set(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(bus.written) > 0 {
+		t.Errorf("got %d writes setting an unchanged value, want nothing sent", len(bus.written))
+	}
+
+	f.Invalidate()
+	err = f.Set("100.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bus.written) == 0 {
+		t.Error("the value wasn't written again after Invalidate")
+	}
+}
@@ -0,0 +1,197 @@
+package hd44780
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// FrameBuffer holds the contents of the display in memory, it's changed with Set and written to the display with
+// Flush, which only sends the cells that have changed since the last flush. It's safe to use from multiple
+// goroutines.
+type FrameBuffer struct {
+	hd *Hd44780I2c
+	mu sync.Mutex
+	// cells is what should be on the display, shown is what was there after the last flush
+	cells, shown [][]byte
+	flushed      bool
+	// open is the number of transactions begun but not committed yet
+	open int
+}
+
+// NewFrameBuffer returns a FrameBuffer the size of hd, it starts out blank.
+func NewFrameBuffer(hd *Hd44780I2c) *FrameBuffer {
+	fb := &FrameBuffer{hd: hd, cells: make([][]byte, hd.rows), shown: make([][]byte, hd.rows)}
+	for r := range fb.cells {
+		fb.cells[r] = make([]byte, hd.cols)
+		fb.shown[r] = make([]byte, hd.cols)
+		for c := range fb.cells[r] {
+			fb.cells[r][c] = ' '
+		}
+	}
+	return fb
+}
+
+// Set puts text into the buffer starting at col on line, text that goes past the end of the line is truncated.
+// ErrInvalidLine or ErrInvalidPos is returned if the start isn't on the display.
+func (fb *FrameBuffer) Set(text string, line, col byte) error {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	if int(line) >= len(fb.cells) {
+		return fmt.Errorf("%w: %d", ErrInvalidLine, line)
+	}
+	row := fb.cells[line]
+	if int(col) >= len(row) {
+		return fmt.Errorf("%w: %d", ErrInvalidPos, col)
+	}
+	for _, c := range text {
+		if int(col) >= len(row) {
+			break
+		}
+		row[col] = fb.hd.charCode(c)
+		col++
+	}
+	return nil
+}
+
+// Clear blanks the buffer, the display isn't changed until the next Flush.
+func (fb *FrameBuffer) Clear() {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	for _, row := range fb.cells {
+		for c := range row {
+			row[c] = ' '
+		}
+	}
+}
+
+// Flush writes the cells that have changed since the last flush to the display, on each line the cells from the
+// first change to the last are rewritten. The whole buffer is written the first time. It's written in a Transaction,
+// so other goroutines should write to the display in transactions too.
+func (fb *FrameBuffer) Flush() error {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	return fb.flush()
+}
+
+// Begin starts a transaction, changes made with Set up until the matching Commit are written to the display
+// together. Transactions from different goroutines can overlap, in which case everything is flushed after the last
+// one is committed rather than once for each. Auto flushing (see StartAutoFlush) waits until there are no
+// transactions open so they're never shown half done.
+func (fb *FrameBuffer) Begin() {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	fb.open++
+}
+
+// Commit ends a transaction started with Begin, if it's the last one open the changes are flushed to the display
+// and any error flushing is returned. ErrCommitWithoutBegin is returned if there's no transaction open.
+func (fb *FrameBuffer) Commit() error {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	if fb.open == 0 {
+		return ErrCommitWithoutBegin
+	}
+	fb.open--
+	if fb.open > 0 {
+		return nil
+	}
+	return fb.flush()
+}
+
+// flush is Flush for callers that hold the lock. The display is written to in a Transaction so flushes don't get
+// mixed up with other writes.
+func (fb *FrameBuffer) flush() error {
+	return fb.hd.Transaction(fb.flushTx)
+}
+
+// flushTx writes the changed cells with tx.
+func (fb *FrameBuffer) flushTx(tx *Tx) error {
+	for r, row := range fb.cells {
+		first, last := -1, -1
+		for c := range row {
+			if !fb.flushed || row[c] != fb.shown[r][c] {
+				if first < 0 {
+					first = c
+				}
+				last = c
+			}
+		}
+		if first < 0 {
+			continue
+		}
+
+		// in entry decrement mode the cursor moves left so the changed cells are written from the right
+		start, step := first, 1
+		if !tx.EntryIncrementEnabled() {
+			start, step = last, -1
+		}
+		err := tx.SetCursor(byte(r), byte(start))
+		if err != nil {
+			return err
+		}
+		for c := start; c >= first && c <= last; c += step {
+			err = tx.WriteChar(row[c])
+			if err != nil {
+				return err
+			}
+			fb.shown[r][c] = row[c]
+		}
+	}
+	fb.flushed = true
+	return nil
+}
+
+// StartAutoFlush flushes the buffer every interval in a new goroutine so callers only need to Set its contents.
+// Calling the returned function stops the flushing, it waits for a flush in progress to finish and returns the
+// first error a flush returned, flushing stops at the first error.
+func (fb *FrameBuffer) StartAutoFlush(interval time.Duration) (stop func() error) {
+	done := make(chan struct{})
+	stopped := make(chan error, 1)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				stopped <- nil
+				return
+			case <-ticker.C:
+				err := fb.autoFlush()
+				if err != nil {
+					<-done
+					stopped <- err
+					return
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	var err error
+	return func() error {
+		once.Do(func() {
+			close(done)
+			err = <-stopped
+		})
+		return err
+	}
+}
+
+// autoFlush flushes the buffer unless there's a transaction open.
+func (fb *FrameBuffer) autoFlush() error {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	if fb.open > 0 {
+		return nil
+	}
+	return fb.flush()
+}
@@ -0,0 +1,145 @@
+package hd44780
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFrameBufferFlush(t *testing.T) {
+	hd, bus := newTestDisplay(t)
+	fb := NewFrameBuffer(hd)
+
+	err := fb.Set("hello", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = fb.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus.written = nil
+
+	// only the changed cells of the first line are rewritten
+	err = fb.Set("help", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = fb.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []instruction{
+		{registerSelectLow, lcdSetDDRamAddr | 0x03},
+		{registerSelectHigh, 'p'},
+	}
+	got := bus.instructions(hd.PinMap)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got instructions %#v, want %#v", got, want)
+	}
+}
+
+func TestFrameBufferAutoFlush(t *testing.T) {
+	hd, bus := newTestDisplay(t)
+	fb := NewFrameBuffer(hd)
+
+	stop := fb.StartAutoFlush(time.Millisecond)
+	err := fb.Set("hi", 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	err = stop()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hd.ddram[0][0x40] != 'h' || hd.ddram[0][0x41] != 'i' {
+		t.Errorf("got %q on the second line, want it to start with %q", hd.ddram[0][0x40:0x42], "hi")
+	}
+	if len(bus.written) == 0 {
+		t.Error("nothing was written")
+	}
+}
+
+func TestFrameBufferTransaction(t *testing.T) {
+	hd, bus := newTestDisplay(t)
+	fb := NewFrameBuffer(hd)
+	err := fb.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus.written = nil
+
+	fb.Begin()
+	fb.Begin()
+	err = fb.Set("a", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = fb.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bus.written) > 0 {
+		t.Fatal("flushed with a transaction still open")
+	}
+
+	err = fb.Set("b", 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = fb.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []instruction{
+		{registerSelectLow, lcdSetDDRamAddr | 0x00},
+		{registerSelectHigh, 'a'},
+		{registerSelectLow, lcdSetDDRamAddr | 0x40},
+		{registerSelectHigh, 'b'},
+	}
+	got := bus.instructions(hd.PinMap)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got instructions %#v, want %#v", got, want)
+	}
+
+	err = fb.Commit()
+	if !errors.Is(err, ErrCommitWithoutBegin) {
+		t.Errorf("got %v from Commit without Begin, want ErrCommitWithoutBegin", err)
+	}
+}
+
+func TestFrameBufferFlushWaitsForTransaction(t *testing.T) {
+	hd, _ := newTestDisplay(t)
+	fb := NewFrameBuffer(hd)
+
+	locked, release := make(chan struct{}), make(chan struct{})
+	txDone := make(chan error, 1)
+	go func() {
+		txDone <- hd.Transaction(func(tx *Tx) error {
+			close(locked)
+			<-release
+			return nil
+		})
+	}()
+	<-locked
+
+	flushed := make(chan error, 1)
+	go func() { flushed <- fb.Flush() }()
+	select {
+	case <-flushed:
+		t.Fatal("Flush wrote to the display during a transaction")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(release)
+	for _, done := range []chan error{txDone, flushed} {
+		if err := <-done; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package hd44780
+
+// PinWriter sets the level of a GPIO pin, pin is a pin number from the I2CPinMap which the PinWriter maps to a
+// GPIO line, eg.
+//
+//	type rpiPins []gpio.PinOut // indexed by pin map number
+//
+//	func (p rpiPins) Set(pin int, high bool) error { return p[pin].Out(gpio.Level(high)) }
+type PinWriter interface {
+	Set(pin int, high bool) error
+}
+
+// GPIOBus adapts GPIO pins wired directly to the HD44780 to a BusWriter, so the display is driven by exactly the
+// same code as one on a port expander. Each byte written is treated as the state of pins 0 - 7 (2 bytes for pins
+// 0 - 15 in 8-bit mode) and only the pins that change are set, EN always changes on its own so the other pins are
+// settled before it goes high. After a write fails every pin is set by the next one.
+type GPIOBus struct {
+	Pins PinWriter
+
+	state uint16
+	set   bool
+}
+
+// Write implements BusWriter.
+func (g *GPIOBus) Write(buf []byte) (int, error) {
+	var state uint16
+	nPins := 8 * len(buf)
+	for i, b := range buf {
+		state |= uint16(b) << (8 * i)
+	}
+
+	for pin := 0; pin < nPins && pin < 16; pin++ {
+		high := state&(0x01<<pin) > 0
+		if g.set && high == (g.state&(0x01<<pin) > 0) {
+			continue
+		}
+		err := g.Pins.Set(pin, high)
+		if err != nil {
+			// some pins may have changed, the next write sets them all as their levels aren't known
+			g.set = false
+			return 0, err
+		}
+	}
+	g.state, g.set = state, true
+	return len(buf), nil
+}
+
+// NewHd44780GPIO returns a new Connection for a display wired directly to GPIO pins, usually in 4-bit mode with RW
+// tied low. The pin map gives the numbers passed to pins for each of the HD44780's pins, pins that aren't wired
+// (eg Backlight) can be ignored by the PinWriter.
+func NewHd44780GPIO(pins PinWriter, pinMap I2CPinMap, rowAddr RowAddress, modes ...ModeSetter) (*Hd44780I2c, error) {
+	return NewHd44780(&GPIOBus{Pins: pins}, pinMap, rowAddr, modes...)
+}
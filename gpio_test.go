@@ -0,0 +1,73 @@
+package hd44780
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type pinSet struct {
+	pin  int
+	high bool
+}
+
+// fakePins records every pin set, setting failPin fails.
+type fakePins struct {
+	sets    []pinSet
+	failPin int
+}
+
+func (p *fakePins) Set(pin int, high bool) error {
+	if pin == p.failPin {
+		return errors.New("gpio error")
+	}
+	p.sets = append(p.sets, pinSet{pin, high})
+	return nil
+}
+
+func TestGPIOBus(t *testing.T) {
+	pins := &fakePins{failPin: -1}
+	bus := &GPIOBus{Pins: pins}
+
+	for _, b := range []byte{0x81, 0x85, 0x81} {
+		_, err := bus.Write([]byte{b})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// all pins are set the first time, then only the ones that change
+	want := []pinSet{
+		{0, true}, {1, false}, {2, false}, {3, false}, {4, false}, {5, false}, {6, false}, {7, true},
+		{2, true},
+		{2, false},
+	}
+	if !reflect.DeepEqual(pins.sets, want) {
+		t.Errorf("got %v, want %v", pins.sets, want)
+	}
+}
+
+func TestGPIOBusFailedWrite(t *testing.T) {
+	pins := &fakePins{failPin: -1}
+	bus := &GPIOBus{Pins: pins}
+	_, err := bus.Write([]byte{0x00})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// pin 1 is set but pin 2 fails, so going back to 0x00 has to set pin 1 again
+	pins.failPin = 2
+	_, err = bus.Write([]byte{0x06})
+	if err == nil {
+		t.Fatal("got no error when setting a pin failed")
+	}
+	pins.failPin, pins.sets = -1, nil
+	_, err = bus.Write([]byte{0x00})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []pinSet{{0, false}, {1, false}, {2, false}, {3, false}, {4, false}, {5, false}, {6, false}, {7, false}}
+	if !reflect.DeepEqual(pins.sets, want) {
+		t.Errorf("got %v, want %v", pins.sets, want)
+	}
+}
@@ -3,7 +3,12 @@ package hd44780
 
 import (
 	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/d2r2/go-i2c"
 )
@@ -11,7 +16,16 @@ import (
 type entryMode byte
 type displayMode byte
 type functionMode byte
-type registerSelect byte
+
+// RegisterSelect is the register a byte is written to, see OnWrite.
+type RegisterSelect byte
+
+const (
+	// InstructionRegister is where instructions are written, RS low.
+	InstructionRegister RegisterSelect = registerSelectLow
+	// DataRegister is where characters and custom character lines are written, RS high.
+	DataRegister RegisterSelect = registerSelectHigh
+)
 
 // CustomChar represents the data for a custom character. Only bits 0 - 4 are used (least significant).
 // Index 0 is the topmost line, bits set to 1 are 'on'. There's a nice generator that outputs hex
@@ -43,6 +57,7 @@ const (
 	writeDelay = 40 * time.Microsecond
 	pulseDelay = 1 * time.Microsecond
 	clearDelay = 1640 * time.Microsecond
+	homeDelay  = 1640 * time.Microsecond
 
 	// delays from datasheet https://www.sparkfun.com/datasheets/LCD/HD44780.pdf
 	initDelay1 = 4100 * time.Microsecond
@@ -86,10 +101,6 @@ const (
 	lcd5x10Dots        functionMode = 0x04 // 00000100
 	lcdSetFunctionMode functionMode = 0x20 // 00100000
 
-	// Backlight Control
-	lcdBacklightOff byte = 0x00
-	lcdBacklightOn  byte = 0x08
-
 	// these appear to be different from https://github.com/davecheney/i2c/blob/master/helloworld/main.go#L15
 	// same as https://github.com/d2r2/go-hd44780/blob/master/lcd.go#L41-L43
 	// https://github.com/kidoman/embd/blob/master/controller/hd44780/hd44780.go#L571-L576
@@ -97,8 +108,8 @@ const (
 	enableBit    byte = 0x4 // EN
 	readWriteBit byte = 0x2 // RW
 
-	registerSelectHigh registerSelect = 0x1
-	registerSelectLow  registerSelect = 0x0
+	registerSelectHigh RegisterSelect = 0x1
+	registerSelectLow  RegisterSelect = 0x0
 
 	// read bits
 	busyBit byte = 0x80
@@ -114,17 +125,29 @@ var (
 	RowAddress16Col RowAddress = [4]byte{0x00, 0x40, 0x10, 0x50}
 	// RowAddress20Col are row addresses for a 20-column display
 	RowAddress20Col RowAddress = [4]byte{0x00, 0x40, 0x14, 0x54}
+	// RowAddress40x4 are row addresses for a 40x4 display with 2 controllers, see DualController
+	RowAddress40x4 RowAddress = [4]byte{0x00, 0x40, 0x00, 0x40}
 )
 
 // I2CPinMap represents a mapping between the pins on an I²C port expander and
 // the pins on the HD44780 controller.
+//
+// D0 - D3 are only used in 8-bit bus mode, which needs more than the 8 pins of a PCF8574 so is only possible with
+// a 16-bit port expander (eg PCF8575), pins on such an expander are numbered 0 - 15.
+//
+// Backlight is NoPin on backpacks where the backlight isn't controlled by the port expander (eg it's always on), then
+// the backlight methods do nothing. It has to be set explicitly, the zero value is pin 0.
 type I2CPinMap struct {
 	RS, RW, EN     byte
+	D0, D1, D2, D3 byte
 	D4, D5, D6, D7 byte
 	Backlight      byte
 	BLPolarity     BacklightPolarity
 }
 
+// NoPin is the backlight pin of an I2CPinMap for a backlight that isn't connected to the port expander.
+const NoPin byte = 0xff
+
 var (
 	// MJKDZPinMap is the standard pin mapping for an MJKDZ-based I²C backpack.
 	MJKDZPinMap I2CPinMap = I2CPinMap{
@@ -142,83 +165,433 @@ var (
 	}
 )
 
+// pinMaps are the pin maps of common backpacks by name, most use the same wiring as the PCF8574 backpack.
+var pinMaps = map[string]I2CPinMap{
+	"mjkdz":     MJKDZPinMap,
+	"gy-lcd":    MJKDZPinMap, // GY-LCD-V1, wired like the MJKDZ
+	"pcf8574":   PCF8574PinMap,
+	"ywrobot":   PCF8574PinMap, // YwRobot LCM1602
+	"sainsmart": PCF8574PinMap, // SainSmart IIC/I2C/TWI
+	"dfrobot":   PCF8574PinMap, // DFRobot I2C LCD backpack
+}
+
+// PinMapByName returns the pin map of a common backpack by name so it can be chosen in a config file or flag, the
+// names are mjkdz, gy-lcd, pcf8574, ywrobot, sainsmart and dfrobot (case doesn't matter). ok is false if the name
+// isn't known.
+func PinMapByName(name string) (pm I2CPinMap, ok bool) {
+	pm, ok = pinMaps[strings.ToLower(name)]
+	return pm, ok
+}
+
+// Validate returns ErrInvalidPinMap if a pin used in 4-bit bus mode (all but D0 - D3) isn't one of the 8 pins of a
+// PCF8574 (0 - 7) or two of them are the same pin, Backlight can also be NoPin. The constructors check the pin map
+// so it only needs calling to check a pin map from a config file before it's used.
+func (pm I2CPinMap) Validate() error {
+	return validatePins(pm.pins4(), 8)
+}
+
+// namedPin is a pin of a pin map and the name of its field.
+type namedPin struct {
+	name string
+	pin  byte
+}
+
+// pins4 returns the pins used in 4-bit bus mode, the backlight pin isn't included if it's NoPin.
+func (pm I2CPinMap) pins4() []namedPin {
+	pins := []namedPin{
+		{"RS", pm.RS}, {"RW", pm.RW}, {"EN", pm.EN},
+		{"D4", pm.D4}, {"D5", pm.D5}, {"D6", pm.D6}, {"D7", pm.D7},
+	}
+	if pm.Backlight != NoPin {
+		pins = append(pins, namedPin{"Backlight", pm.Backlight})
+	}
+	return pins
+}
+
+// validatePins returns ErrInvalidPinMap if a pin isn't below n or two pins are the same.
+func validatePins(pins []namedPin, n byte) error {
+	used := make(map[byte]string, len(pins))
+	for _, p := range pins {
+		if p.pin >= n {
+			return fmt.Errorf("%w: %s is pin %d, the pins are 0 - %d", ErrInvalidPinMap, p.name, p.pin, n-1)
+		}
+		if other, ok := used[p.pin]; ok {
+			return fmt.Errorf("%w: %s and %s are both pin %d", ErrInvalidPinMap, other, p.name, p.pin)
+		}
+		used[p.pin] = p.name
+	}
+	return nil
+}
+
+// validatePinMap checks the pin map for the bus mode, 8-bit mode uses D0 - D3 as well on a 16-bit expander. With
+// DualController the second EN pin replaces RW, which it's usually connected to.
+func (hd *Hd44780I2c) validatePinMap() error {
+	pm := hd.PinMap
+	if !hd.EightBitModeEnabled() && !hd.dual {
+		return pm.Validate()
+	}
+
+	pins, n := pm.pins4(), byte(8)
+	if hd.dual {
+		pins[1] = namedPin{"DualController EN", hd.en2}
+	}
+	if hd.EightBitModeEnabled() {
+		pins = append(pins, namedPin{"D0", pm.D0}, namedPin{"D1", pm.D1}, namedPin{"D2", pm.D2}, namedPin{"D3", pm.D3})
+		n = 16
+	}
+	return validatePins(pins, n)
+}
+
 type Hd44780I2c struct {
-	I2C       *i2c.I2C
-	PinMap    I2CPinMap
-	RowAddr   RowAddress
+	// I2C is the connection passed to NewHd44780I2c, it's nil if the display was created with another constructor.
+	I2C     *i2c.I2C
+	PinMap  I2CPinMap
+	RowAddr RowAddress
+	// CloseBus makes Close also close the I²C connection, leave it false if the bus is shared with other devices.
+	CloseBus bool
+	// VerifyWrites makes every character (or custom character line) written be read back and compared, a mismatch
+	// returns ErrVerifyFailed. It's for debugging wiring and is slow, RW must be wired and the bus must implement
+	// io.Reader.
+	VerifyWrites bool
+	// OnWrite is called with every byte written to the controller once it's been written, eg to log what's sent
+	// when debugging. rs is InstructionRegister or DataRegister. In 4-bit mode the byte is sent as 2 nibbles, high
+	// first, see OnNibble.
+	OnWrite func(data byte, rs RegisterSelect)
+	// OnNibble is called with every nibble written to the controller in 4-bit mode once it's been written, in the
+	// low 4 bits of nibble, high says whether it's the high nibble of a byte. The single nibbles sent by Resync are
+	// reported as high nibbles as the controller may still be in 8-bit mode.
+	OnNibble func(nibble byte, rs RegisterSelect, high bool)
+	// Logf is called with messages about what the driver does by itself, eg when the watchdog (see Watchdog)
+	// re-initialises the display. log.Printf can be used.
+	Logf func(format string, args ...interface{})
+	// OnCell is called when a cell on the display changes, with its row and column and the character code that's
+	// now in it, eg to show the display in a simulator. Cells are tracked as characters are written and the display
+	// is cleared, display shifts aren't taken into account so with ShiftDisplay or EntryShiftOn the row and column
+	// are of the cell in DDRAM rather than where it's shown.
+	OnCell func(row, col, code byte)
+	// inCGRAM is true when the address counter was last set to a CGRAM address
+	inCGRAM   bool
+	bus       BusWriter
 	backlight bool
 	eMode     entryMode
 	dMode     displayMode
 	fMode     functionMode
+	rows      byte
+	cols      byte
+	skipInit  bool
+	checkConn bool
+	curRow    byte
+	curCol    byte
+	// retries is the number of times a failed bus write is retried, the first retry is after retryBackoff which is
+	// doubled for each retry after that
+	retries      int
+	retryBackoff time.Duration
+	// opTimeout is set with SetOpTimeout, opDeadline is when the operation in progress times out (zero if there's no
+	// deadline) and needsResync is set once one has, stuck is closed when a write that timed out returns (nil if
+	// there isn't one)
+	opTimeout     time.Duration
+	opDeadline    time.Time
+	needsResync   bool
+	stuck         chan struct{}
+	transliterate bool
+	// ddram is a copy of what's been written to the DDRAM of each controller, only the first is used without
+	// DualController
+	ddram [2][0x80]byte
+	// sentEntry, sentDisplay and sentFunction are the last mode instructions sent, 0 when it isn't known what the
+	// controller has
+	sentEntry, sentDisplay, sentFunction byte
+	strictChars                          bool
+	shiftDelay                           time.Duration
+	// parkRow and parkCol are where ParkCursor moves the cursor to if parkSet, otherwise it's the last cell
+	parkRow, parkCol byte
+	parkSet          bool
+	autoPark         bool
+	// hiddenCursor is the cursor flags that were on when HideCursor was called
+	hiddenCursor displayMode
+	cursorHidden bool
+	// splitAt is the first column that's in the second line of DDRAM with Split16x1, 0 for a normal display
+	splitAt byte
+	// initFunc replaces lcdInit if it's set
+	initFunc func(hd *Hd44780I2c) error
+	// dual is set for displays with 2 controllers, en2 is the EN pin of the second and ctrl is the one the cursor is
+	// on (0 or 1)
+	dual bool
+	en2  byte
+	ctrl byte
+	// timing is the delays used to wait for the controller
+	timing   Timing
+	pollBusy bool
+	contrast ContrastController
+	wrap     lineWrap
+	// noInitialClear stops runInit clearing the display at the end
+	noInitialClear bool
+	// mu is held for the length of a Transaction
+	mu sync.Mutex
+	// rotated is set by Rotate180
+	rotated bool
+	// strictWidth is set by StrictOverflow
+	strictWidth bool
+	// barChars is set when the custom characters CenterBar uses are loaded
+	barChars bool
+	// watchdog is the number of writes in a row that have to fail for the display to be re-initialised, 0 if
+	// the watchdog is off. failRun is the number of writes in a row that have failed, reiniting is set while it's
+	// being re-initialised.
+	watchdog  int
+	failRun   int
+	reiniting bool
+	// verifyBusMode is set by VerifyBusMode
+	verifyBusMode bool
+	// modeErr is set by a ModeSetter that was given an invalid option, the constructor or SetMode returns it
+	modeErr error
 }
 
 // NewHd44780I2c returns a new Connection based on an I²C bus.
 func NewHd44780I2c(i2c *i2c.I2C, pinMap I2CPinMap, rowAddr RowAddress, modes ...ModeSetter) (*Hd44780I2c, error) {
+	c, err := NewHd44780(i2c, pinMap, rowAddr, modes...)
+	if err != nil {
+		return nil, err
+	}
+	c.I2C = i2c
+	return c, nil
+}
+
+// NewHd44780 returns a new Connection that writes to the port expander with bus, use it when the port expander
+// isn't connected with github.com/d2r2/go-i2c. An error wrapping ErrInvalidPinMap is returned before anything is
+// written if the pin map isn't valid (see I2CPinMap.Validate), an InitError if the display can't be initialised.
+func NewHd44780(bus BusWriter, pinMap I2CPinMap, rowAddr RowAddress, modes ...ModeSetter) (*Hd44780I2c, error) {
 	c := &Hd44780I2c{
-		I2C:       i2c,
+		bus:       bus,
 		PinMap:    pinMap,
 		RowAddr:   rowAddr,
 		backlight: true,
+		timing:    DefaultTiming,
 		eMode:     0x00,
 		dMode:     0x00,
 		fMode:     0x00,
 	}
+	c.clearDDRAM()
 
-	err := c.lcdInit()
+	// the init sequence depends on the bus mode so the modes need to be known before it runs
+	for _, m := range append(DefaultModes, modes...) {
+		m(c)
+	}
+	if c.modeErr != nil {
+		return nil, c.modeErr
+	}
+	c.setDefaultDimensions()
+
+	err := c.validatePinMap()
 	if err != nil {
 		return nil, err
 	}
+	// RW can't be raised to read when it's the second controller's EN
+	if c.dual && c.en2 == c.PinMap.RW && (c.pollBusy || c.verifyBusMode || c.checkConn) {
+		return nil, fmt.Errorf("%w: PollBusyFlag, VerifyBusMode and CheckConnection need RW, which is the second "+
+			"controller's EN", ErrReadNotSupported)
+	}
+	// the check needs reads, a bus without them says nothing about whether the display is responding
+	if _, ok := bus.(io.Reader); c.checkConn && !ok {
+		return nil, ErrReadNotSupported
+	}
+
+	err = c.probe()
+	if err != nil {
+		return nil, &InitError{Stage: ErrBusUnreachable, Err: err}
+	}
+
+	if !c.skipInit {
+		err = c.runInit()
+		if err != nil {
+			return nil, &InitError{Stage: ErrInitFailed, Err: err}
+		}
+	}
 
 	err = c.SetMode(append(DefaultModes, modes...)...)
 	if err != nil {
-		return nil, err
+		return nil, &InitError{Stage: ErrInitFailed, Err: err}
+	}
+
+	if c.checkConn {
+		err = c.checkConnection()
+		if err != nil {
+			return nil, &InitError{Stage: ErrNotResponding, Err: err}
+		}
 	}
 
 	return c, nil
 }
 
+// probe checks that the bus can be written to by writing the idle state of the pins, nothing is sent to the
+// controller as EN stays low.
+func (hd *Hd44780I2c) probe() error {
+	var idle uint16 = 0x00
+	idle |= hd.backlightBit()
+	return hd.writePins(idle)
+}
+
+// setDefaultDimensions fills in the number of rows and columns if they haven't been set with Dimensions. The columns
+// are worked out from the row addresses and the rows from the line mode, which is right for 16x2 and 20x2 displays,
+// but 4 row displays use 2-line mode so they need to be set explicitly.
+func (hd *Hd44780I2c) setDefaultDimensions() {
+	if hd.cols == 0 {
+		hd.cols = 16
+		if hd.RowAddr[2] > hd.RowAddr[0] {
+			hd.cols = hd.RowAddr[2] - hd.RowAddr[0]
+		}
+	}
+	if hd.rows == 0 {
+		hd.rows = 1
+		if hd.TwoLineEnabled() {
+			hd.rows = 2
+		}
+	}
+}
+
+// runInit runs the init sequence, the one set with InitFunc if there is one, then checks the bus mode if
+// VerifyBusMode is set and clears the display unless NoInitialClear is set. What's been sent to the mode registers
+// is forgotten as init changes them.
+func (hd *Hd44780I2c) runInit() error {
+	hd.sentEntry, hd.sentDisplay, hd.sentFunction = 0, 0, 0
+	var err error
+	if hd.initFunc != nil {
+		err = hd.initFunc(hd)
+	} else {
+		err = hd.lcdInit()
+	}
+	if err != nil {
+		return err
+	}
+
+	if hd.verifyBusMode {
+		err = hd.verifyMode()
+		if err != nil {
+			return err
+		}
+	}
+
+	if hd.noInitialClear {
+		return nil
+	}
+	return hd.Clear()
+}
+
+// Reinit initialises the display again and puts back what was on it, eg after it's lost power. The modes, backlight,
+// cursor position and DDRAM are restored as by RestoreState but custom characters aren't, load them again if they're
+// used.
+func (hd *Hd44780I2c) Reinit() error {
+	state := hd.SaveState()
+	// CGRAM may have been lost
+	hd.barChars = false
+	err := hd.runInit()
+	if err != nil {
+		return err
+	}
+	err = hd.SetMode()
+	if err != nil {
+		return err
+	}
+	hd.needsResync = false
+	return hd.RestoreState(state)
+}
+
+// StandardInit sends the standard HD44780 init sequence, it's what the constructors do by default. It's for init
+// functions set with InitFunc that need to send extra instructions before or after it. It doesn't clear the display,
+// that's done after the init function returns.
+func StandardInit(hd *Hd44780I2c) error {
+	return hd.lcdInit()
+}
+
 func (hd *Hd44780I2c) lcdInit() error {
+	// in 8-bit mode the whole init byte is sent at once, in 4-bit mode it's sent as 2 nibbles (0x0 then 0x3)
+	var initInstruction byte = 0x03
+	if hd.EightBitModeEnabled() {
+		initInstruction = 0x30
+	}
+
 	time.Sleep(time.Millisecond * 20)
-	err := hd.WriteInstruction(0x03) // init
+	err := hd.WriteInstruction(initInstruction) // init
 	if err != nil {
 		return err
 	}
 
 	time.Sleep(initDelay1)
 
-	err = hd.WriteInstruction(0x03) // init
+	err = hd.WriteInstruction(initInstruction) // init
 	if err != nil {
 		return err
 	}
 
 	time.Sleep(initDelay2)
 
-	err = hd.WriteInstruction(0x03) // init
+	err = hd.WriteInstruction(initInstruction) // init
 	if err != nil {
 		return err
 	}
 
-	err = hd.WriteInstruction(0x02) // 4 bit mode
+	if !hd.EightBitModeEnabled() {
+		return hd.WriteInstruction(0x02) // 4 bit mode
+	}
+	return nil
+}
+
+// Resync gets the controller back in step after it's lost track of which nibble is which, eg when a write was
+// interrupted between the 2 nibbles, which makes everything after it garbage. The init sequence (0x3 three times
+// then 0x2) is sent as single nibbles, which puts the controller in 4-bit mode whatever state it was in, then the
+// function mode and the address of the tracked cursor are sent again. Unlike the full init the display isn't
+// cleared, so the content that was corrupted can be rewritten. In 8-bit mode there are no nibbles to get out of
+// step so only the function mode is resent.
+func (hd *Hd44780I2c) Resync() error {
+	if !hd.EightBitModeEnabled() {
+		for _, d := range []time.Duration{initDelay1, initDelay2, initDelay2} {
+			err := hd.writeNibble(0x03, true, registerSelectLow, hd.allEnableBits())
+			if err != nil {
+				return err
+			}
+			time.Sleep(d)
+		}
+		err := hd.writeNibble(0x02, true, registerSelectLow, hd.allEnableBits()) // 4 bit mode
+		if err != nil {
+			return err
+		}
+		time.Sleep(hd.timing.Write)
+	}
+
+	err := hd.ApplyFunctionMode()
 	if err != nil {
 		return err
 	}
-
-	return hd.Clear()
+	err = hd.WriteInstruction(lcdSetDDRamAddr | hd.cursorAddress())
+	if err != nil {
+		return err
+	}
+	hd.needsResync = false
+	return nil
 }
 
 // SetModes modifies the entry mode, display mode, and function mode with the
-// given mode setter functions.
+// given mode setter functions. Only the registers that are different from what was last sent to the display are
+// written.
 func (hd *Hd44780I2c) SetMode(modes ...ModeSetter) error {
 	for _, m := range modes {
 		m(hd)
 	}
-	functions := []func() error{
-		func() error { return hd.setEntryMode() },
-		func() error { return hd.setDisplayMode() },
-		func() error { return hd.setFunctionMode() },
+	if err := hd.modeErr; err != nil {
+		hd.modeErr = nil
+		return err
 	}
-	for _, f := range functions {
-		err := f()
+	registers := []struct {
+		ins, sent byte
+		apply     func() error
+	}{
+		{byte(lcdSetEntryMode | hd.eMode), hd.sentEntry, hd.ApplyEntryMode},
+		{byte(lcdSetDisplayMode | hd.dMode), hd.sentDisplay, hd.ApplyDisplayMode},
+		{byte(lcdSetFunctionMode | hd.fMode), hd.sentFunction, hd.ApplyFunctionMode},
+	}
+	for _, r := range registers {
+		if r.ins == r.sent {
+			continue
+		}
+		err := r.apply()
 		if err != nil {
 			return err
 		}
@@ -226,49 +599,154 @@ func (hd *Hd44780I2c) SetMode(modes ...ModeSetter) error {
 	return nil
 }
 
-func (hd *Hd44780I2c) setEntryMode() error {
+// ApplyEntryMode sends the entry mode flags to the display, use it after applying entry ModeSetters directly
+// (eg hd44780.EntryDecrement(hd)) to send just the register that changed rather than all 3 with SetMode.
+func (hd *Hd44780I2c) ApplyEntryMode() error {
 	return hd.WriteInstruction(byte(lcdSetEntryMode | hd.eMode))
 }
 
-func (hd *Hd44780I2c) setDisplayMode() error {
+// ApplyDisplayMode sends the display mode flags (display, cursor and blink) to the display, see ApplyEntryMode.
+func (hd *Hd44780I2c) ApplyDisplayMode() error {
 	return hd.WriteInstruction(byte(lcdSetDisplayMode | hd.dMode))
 }
 
-func (hd *Hd44780I2c) setFunctionMode() error {
+// ApplyFunctionMode sends the function mode flags (bus mode, lines and dots) to the display, see ApplyEntryMode.
+func (hd *Hd44780I2c) ApplyFunctionMode() error {
 	return hd.WriteInstruction(byte(lcdSetFunctionMode | hd.fMode))
 }
 
 // write writes a register select flag and byte to the I²C connection.
-func (hd *Hd44780I2c) write(data byte, rs registerSelect) error {
-	var instructionHigh byte = 0x00
-	instructionHigh |= ((data >> 4) & 0x01) << hd.PinMap.D4
-	instructionHigh |= ((data >> 5) & 0x01) << hd.PinMap.D5
-	instructionHigh |= ((data >> 6) & 0x01) << hd.PinMap.D6
-	instructionHigh |= ((data >> 7) & 0x01) << hd.PinMap.D7
-
-	var instructionLow byte = 0x00
-	instructionLow |= ((data >> 0) & 0x01) << hd.PinMap.D4
-	instructionLow |= ((data >> 1) & 0x01) << hd.PinMap.D5
-	instructionLow |= ((data >> 2) & 0x01) << hd.PinMap.D6
-	instructionLow |= ((data >> 3) & 0x01) << hd.PinMap.D7
-
-	instructions := []byte{instructionHigh, instructionLow}
-	for _, ins := range instructions {
-		ins |= byte(rs) << hd.PinMap.RS
-		if hd.backlight == bool(hd.PinMap.BLPolarity) {
-			ins |= 0x01 << hd.PinMap.Backlight
+// If VerifyWrites is set data written to RAM is read back and checked.
+func (hd *Hd44780I2c) write(data byte, rs RegisterSelect) error {
+	if rs == registerSelectHigh && hd.inCGRAM {
+		// the custom characters are being replaced
+		hd.barChars = false
+	}
+
+	var err error
+	en := hd.enableBits(data, rs)
+	if hd.EightBitModeEnabled() {
+		err = hd.write8(data, rs, en)
+	} else {
+		err = hd.write4(data, rs, en)
+	}
+	if err != nil {
+		return hd.writeFailed(data, rs, err)
+	}
+	hd.failRun = 0
+	if hd.OnWrite != nil {
+		hd.OnWrite(data, rs)
+	}
+
+	if rs == registerSelectLow {
+		hd.trackRAM(data)
+		hd.trackModes(data)
+		return nil
+	}
+	if hd.VerifyWrites {
+		return hd.verify(data)
+	}
+	return nil
+}
+
+// writeFailed counts a failed write for the watchdog, once enough have failed in a row the display is re-initialised
+// and the write tried again, unless it was to CGRAM as the address to write to is lost. err is returned if the
+// watchdog is off or the display still can't be written to.
+func (hd *Hd44780I2c) writeFailed(data byte, rs RegisterSelect, err error) error {
+	hd.failRun++
+	if hd.watchdog == 0 || hd.failRun < hd.watchdog || hd.reiniting {
+		return err
+	}
+
+	hd.reiniting = true
+	defer func() { hd.reiniting = false }()
+	retry := !hd.inCGRAM
+	hd.logf("hd44780: %d writes failed in a row (%v), re-initialising the display", hd.failRun, err)
+	rerr := hd.Reinit()
+	if rerr != nil {
+		hd.logf("hd44780: re-initialising the display failed: %v", rerr)
+		return err
+	}
+	if !retry {
+		return err
+	}
+	return hd.write(data, rs)
+}
+
+// logf calls Logf if it's set.
+func (hd *Hd44780I2c) logf(format string, args ...interface{}) {
+	if hd.Logf != nil {
+		hd.Logf(format, args...)
+	}
+}
+
+// write4 writes a register select flag and byte to the I²C connection as 2 nibbles, en is the EN pin(s) to pulse.
+func (hd *Hd44780I2c) write4(data byte, rs RegisterSelect, en uint16) error {
+	err := hd.writeNibble(data>>4, true, rs, en)
+	if err != nil {
+		return err
+	}
+	err = hd.writeNibble(data&0x0f, false, rs, en)
+	if err != nil {
+		return err
+	}
+	time.Sleep(hd.timing.Write) // is this necessary with i2c?
+	return nil
+}
+
+// writeNibble writes a register select flag and the low 4 bits of nibble to the I²C connection with a single pulse
+// of the en pin(s), high is whether it's the high nibble of a byte.
+func (hd *Hd44780I2c) writeNibble(nibble byte, high bool, rs RegisterSelect, en uint16) error {
+	var ins byte = 0x00
+	ins |= ((nibble >> 0) & 0x01) << hd.PinMap.D4
+	ins |= ((nibble >> 1) & 0x01) << hd.PinMap.D5
+	ins |= ((nibble >> 2) & 0x01) << hd.PinMap.D6
+	ins |= ((nibble >> 3) & 0x01) << hd.PinMap.D7
+
+	ins |= byte(rs) << hd.PinMap.RS
+	ins |= byte(hd.backlightBit())
+
+	// by default only the EN pulse needs a wait, the setup and hold times either side of it (and the time between
+	// nibbles) are far shorter than a bus write
+	bytes := []byte{ins, ins | byte(en), ins}
+	for i, b := range bytes {
+		err := hd.writeByte(b)
+		if err != nil {
+			return err
 		}
+		time.Sleep(hd.pulseWait(i))
+	}
+	if hd.OnNibble != nil {
+		hd.OnNibble(nibble, rs, high)
+	}
+	return nil
+}
 
-		bytes := []byte{ins, ins | (0x01 << hd.PinMap.EN), ins}
-		for _, b := range bytes {
-			time.Sleep(pulseDelay)
-			_, err := hd.I2C.WriteByte(b)
-			if err != nil {
-				return err
-			}
+// write8 writes a register select flag and byte to a 16-bit port expander in a single (8-bit) transfer, the low
+// byte of the port is sent first. en is the EN pin(s) to pulse.
+func (hd *Hd44780I2c) write8(data byte, rs RegisterSelect, en uint16) error {
+	var ins uint16 = 0x00
+	ins |= uint16((data>>0)&0x01) << hd.PinMap.D0
+	ins |= uint16((data>>1)&0x01) << hd.PinMap.D1
+	ins |= uint16((data>>2)&0x01) << hd.PinMap.D2
+	ins |= uint16((data>>3)&0x01) << hd.PinMap.D3
+	ins |= uint16((data>>4)&0x01) << hd.PinMap.D4
+	ins |= uint16((data>>5)&0x01) << hd.PinMap.D5
+	ins |= uint16((data>>6)&0x01) << hd.PinMap.D6
+	ins |= uint16((data>>7)&0x01) << hd.PinMap.D7
+
+	ins |= uint16(rs) << hd.PinMap.RS
+	ins |= hd.backlightBit()
+
+	words := []uint16{ins, ins | en, ins}
+	for i, w := range words {
+		err := hd.busWrite([]byte{byte(w), byte(w >> 8)})
+		if err != nil {
+			return err
 		}
+		time.Sleep(hd.pulseWait(i))
 	}
-	time.Sleep(writeDelay) // is this necessary with i2c?
+	time.Sleep(hd.timing.Write)
 	return nil
 }
 
@@ -277,36 +755,40 @@ func (hd *Hd44780I2c) write(data byte, rs registerSelect) error {
 // https://www.eevblog.com/forum/microcontrollers/busy-check-with-hd44780-via-12c/. It does return data but it's the
 // bits set from this end. This is left here in the hope that someone else figures it out.
 func (hd *Hd44780I2c) ReadStatus() (bool, byte, error) {
-	sendByte := byte(0x0) | (0x01 << hd.PinMap.RW)
-	if hd.backlight == bool(hd.PinMap.BLPolarity) {
-		sendByte |= 0x01 << hd.PinMap.Backlight
+	r, err := hd.reader()
+	if err != nil {
+		return false, 0x0, err
 	}
 
+	sendByte := byte(0x0) | (0x01 << hd.PinMap.RW)
+	sendByte |= byte(hd.backlightBit())
+
 	// 1st nibble
-	_, err := hd.I2C.WriteByte(sendByte)
+	err = hd.writeByte(sendByte)
 	if err != nil {
 		return false, 0x0, err
 	}
-	time.Sleep(pulseDelay)
+	time.Sleep(hd.pulseWait(0))
 
 	// toggle enable
-	_, err = hd.I2C.WriteByte(sendByte | (0x01 << hd.PinMap.EN))
+	err = hd.writeByte(sendByte | (0x01 << hd.PinMap.EN))
 	if err != nil {
 		return false, 0x0, err
 	}
-	_, err = hd.I2C.WriteByte(sendByte)
+	time.Sleep(hd.pulseWait(1))
+	err = hd.writeByte(sendByte)
 	if err != nil {
 		return false, 0x0, err
 	}
 
-	time.Sleep(pulseDelay)
+	time.Sleep(hd.pulseWait(2))
 	data1 := make([]byte, 2)
-	size, err := hd.I2C.Read(data1)
+	size, err := r.Read(data1)
 	if err != nil {
 		return false, 0x0, err
 	}
 
-	time.Sleep(pulseDelay)
+	time.Sleep(hd.pulseWait(2))
 
 	// 2nd nibble
 	//_, err = this.I2C.WriteByte(sendByte)
@@ -322,7 +804,7 @@ func (hd *Hd44780I2c) ReadStatus() (bool, byte, error) {
 	//}
 
 	data2 := make([]byte, 1)
-	size, err = hd.I2C.Read(data1)
+	size, err = r.Read(data1)
 	if err != nil {
 		return false, 0x0, err
 	}
@@ -341,34 +823,110 @@ func (hd *Hd44780I2c) ReadStatus() (bool, byte, error) {
 	return false, 0x0, fmt.Errorf("invalid read size: %d", size)
 }
 
-// DisplayString displays the given string at the specified position, line is zero indexed.
+// DisplayString displays the given string at the specified position, line and pos are zero indexed.
+// ErrInvalidLine or ErrInvalidPos is returned if the position isn't on the display. Each rune is written as its low
+// byte unless TransliterateOn is set.
 func (hd *Hd44780I2c) DisplayString(str string, line, pos byte) error {
-	var address byte
-	switch line {
-	case 0:
-		address = hd.RowAddr[0] + pos
-	case 1:
-		address = hd.RowAddr[1] + pos
-	case 2:
-		address = hd.RowAddr[2] + pos
-	case 3:
-		address = hd.RowAddr[3] + pos
+	defer hd.startOp()()
+
+	if n := utf8.RuneCountInString(str); hd.overflowChecked() && n > hd.room(pos) {
+		return fmt.Errorf("%w: %d characters at %d", ErrOverflow, n, pos)
+	}
+	if hd.rotated {
+		return hd.displayRotated(str, line, pos)
 	}
 
-	err := hd.WriteInstruction(lcdSetDDRamAddr + address)
+	err := hd.SetCursor(line, pos)
 	if err != nil {
 		return err
 	}
+
 	for _, c := range str {
-		err = hd.WriteChar(byte(c))
+		err = hd.WriteChar(hd.charCode(c))
 		if err != nil {
 			return err
 		}
 	}
+	if hd.autoPark {
+		return hd.ParkCursor()
+	}
 	return nil
 }
 
+// DisplayStringAt displays the given string starting at a DDRAM address, it's for displays with row addresses that
+// don't fit RowAddress.
+func (hd *Hd44780I2c) DisplayStringAt(addr byte, text string) error {
+	defer hd.startOp()()
+
+	err := hd.SetDDRamAddr(addr)
+	if err != nil {
+		return err
+	}
+	for _, c := range text {
+		err = hd.WriteChar(hd.charCode(c))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DisplayBytes displays the given bytes at the specified position, line and pos are zero indexed. Unlike
+// DisplayString each byte is written as is so it's the simplest way to display custom characters (codes 0 - 7).
+func (hd *Hd44780I2c) DisplayBytes(b []byte, line, pos byte) error {
+	defer hd.startOp()()
+
+	err := hd.SetCursor(line, pos)
+	if err != nil {
+		return err
+	}
+	_, err = hd.Write(b)
+	return err
+}
+
+// BlitRow writes data to line starting at the first column, each byte as is like DisplayBytes, for rows that have
+// already been built as character codes. Bytes past the last column are dropped rather than written into DDRAM
+// that isn't shown. ErrInvalidLine is returned if the line isn't one of the rows of the display.
+func (hd *Hd44780I2c) BlitRow(line byte, data []byte) error {
+	if line >= hd.rows {
+		return fmt.Errorf("%w: %d", ErrInvalidLine, line)
+	}
+	if len(data) > int(hd.cols) {
+		data = data[:hd.cols]
+	}
+	return hd.DisplayBytes(data, line, 0)
+}
+
+// address returns the DDRAM address of the given line and position and the controller that drives the line (see
+// DualController), nothing is changed so it can be used just to check the position. In 1-line mode DDRAM is a single
+// line so only line 0 is valid, in 2-line mode (which 4 row displays also use) it's the rows of the display (see
+// Dimensions).
+func (hd *Hd44780I2c) address(line, pos byte) (address, ctrl byte, err error) {
+	lines := minInt(int(hd.rows), len(hd.RowAddr))
+	if !hd.TwoLineEnabled() || hd.splitAt > 0 {
+		lines = 1
+	}
+	if int(line) >= lines {
+		return 0, 0, fmt.Errorf("%w: %d", ErrInvalidLine, line)
+	}
+	if pos >= hd.cols {
+		return 0, 0, fmt.Errorf("%w: %d", ErrInvalidPos, pos)
+	}
+	return hd.cellAddress(line, pos), hd.rowController(line), nil
+}
+
+// room returns the number of characters that fit on the line from pos to the edge of the display, in entry
+// decrement mode the text runs left from pos so it's the number up to the first column.
+func (hd *Hd44780I2c) room(pos byte) int {
+	if !hd.EntryIncrementEnabled() {
+		return int(pos) + 1
+	}
+	return int(hd.cols) - int(pos)
+}
+
 func (hd *Hd44780I2c) Write(buf []byte) (int, error) {
+	defer hd.startOp()()
+
 	for i, c := range buf {
 		err := hd.WriteChar(c)
 		if err != nil {
@@ -378,79 +936,209 @@ func (hd *Hd44780I2c) Write(buf []byte) (int, error) {
 	return len(buf), nil
 }
 
+// WriteString writes text at the cursor position, unlike DisplayString the address isn't set first so it carries on
+// from whatever was written last (or the position set with SetCursor). Runes are written as they are by
+// DisplayString.
+func (hd *Hd44780I2c) WriteString(text string) error {
+	defer hd.startOp()()
+
+	for _, c := range text {
+		err := hd.WriteChar(hd.charCode(c))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // SetDDRamAddr sets the input cursor to the given address.
 func (hd *Hd44780I2c) SetDDRamAddr(value byte) error {
-	return hd.WriteInstruction(lcdSetDDRamAddr | value)
+	err := hd.WriteInstruction(lcdSetDDRamAddr | value)
+	if err != nil {
+		return err
+	}
+	hd.setCursorFromAddress(value)
+	return nil
 }
 
 // WriteChar writes a byte to the bus with register select in data mode.
 func (hd *Hd44780I2c) WriteChar(value byte) error {
-	return hd.write(value, registerSelectHigh)
+	defer hd.startOp()()
+
+	if hd.wrap != wrapOff && !hd.inCGRAM && hd.EntryIncrementEnabled() && hd.curCol >= hd.cols {
+		err := hd.wrapLine()
+		if err != nil {
+			return err
+		}
+	}
+	if hd.overflowChecked() && !hd.inCGRAM && hd.curCol >= hd.cols {
+		return fmt.Errorf("%w: column %d", ErrOverflow, hd.curCol)
+	}
+	if hd.crossesSplit() {
+		err := hd.WriteInstruction(lcdSetDDRamAddr | hd.cursorAddress())
+		if err != nil {
+			return err
+		}
+	}
+	err := hd.write(value, registerSelectHigh)
+	if err != nil {
+		return err
+	}
+	if !hd.inCGRAM {
+		hd.setCell(value)
+	}
+	hd.advanceCursor()
+	return nil
 }
 
 // WriteInstruction writes a byte to the bus with register select in command mode.
 func (hd *Hd44780I2c) WriteInstruction(value byte) error {
+	defer hd.startOp()()
 	return hd.write(value, registerSelectLow)
 }
 
+// BacklightOn turns the backlight on, it does nothing if the backlight pin is NoPin.
 func (hd *Hd44780I2c) BacklightOn() error {
+	if hd.PinMap.Backlight == NoPin {
+		return nil
+	}
 	hd.backlight = true
-	_, err := hd.I2C.WriteByte(lcdBacklightOn)
-	return err
+	return hd.writePins(hd.backlightBit())
 }
 
+// BacklightOff turns the backlight off, it does nothing if the backlight pin is NoPin.
 func (hd *Hd44780I2c) BacklightOff() error {
+	if hd.PinMap.Backlight == NoPin {
+		return nil
+	}
 	hd.backlight = false
-	_, err := hd.I2C.WriteByte(lcdBacklightOff)
-	return err
+	return hd.writePins(hd.backlightBit())
+}
+
+// SetBacklight turns the backlight on or off and returns whether it was on before, so it can be put back, eg after
+// flashing it to get attention.
+func (hd *Hd44780I2c) SetBacklight(on bool) (prev bool, err error) {
+	prev = hd.backlight
+	if on {
+		return prev, hd.BacklightOn()
+	}
+	return prev, hd.BacklightOff()
+}
+
+// SetBacklightPolarity changes the polarity of the backlight pin, the backlight is set again straight away so it
+// stays on (or off).
+func (hd *Hd44780I2c) SetBacklightPolarity(polarity BacklightPolarity) error {
+	hd.PinMap.BLPolarity = polarity
+	if hd.PinMap.Backlight == NoPin {
+		return nil
+	}
+	return hd.writePins(hd.backlightBit())
+}
+
+// backlightBit returns the value of the backlight pin to be included in every write to the port expander, it's set
+// when the backlight is on with Positive polarity or off with Negative polarity. It's a uint16 as 16-bit expanders
+// can have the backlight on the second port. It's always 0 if the backlight pin is NoPin.
+func (hd *Hd44780I2c) backlightBit() uint16 {
+	if hd.PinMap.Backlight == NoPin || hd.backlight != bool(hd.PinMap.BLPolarity) {
+		return 0x00
+	}
+	return 0x01 << hd.PinMap.Backlight
 }
 
 // DisplayOff sets the display mode to off.
 func (hd *Hd44780I2c) DisplayOff() error {
 	DisplayOff(hd)
-	return hd.setDisplayMode()
+	return hd.ApplyDisplayMode()
 }
 
 // DisplayOn sets the display mode to on.
 func (hd *Hd44780I2c) DisplayOn() error {
 	DisplayOn(hd)
-	return hd.setDisplayMode()
+	return hd.ApplyDisplayMode()
 }
 
 // UnderlineCursorOff turns the cursor off.
 func (hd *Hd44780I2c) UnderlineCursorOff() error {
 	UnderlineCursorOff(hd)
-	return hd.setDisplayMode()
+	return hd.ApplyDisplayMode()
 }
 
 // UnderlineCursorOn turns the cursor on.
 func (hd *Hd44780I2c) UnderlineCursorOn() error {
 	UnderlineCursorOn(hd)
-	return hd.setDisplayMode()
+	return hd.ApplyDisplayMode()
 }
 
 // BlinkCursorOff sets cursor blink mode off.
 func (hd *Hd44780I2c) BlinkCursorOff() error {
 	BlinkCursorOff(hd)
-	return hd.setDisplayMode()
+	return hd.ApplyDisplayMode()
 }
 
 // BlinkCursorOn sets cursor blink mode on.
 func (hd *Hd44780I2c) BlinkCursorOn() error {
 	BlinkCursorOn(hd)
-	return hd.setDisplayMode()
+	return hd.ApplyDisplayMode()
+}
+
+// HideCursor turns off both the underline cursor and cursor blink, remembering which were on so ShowCursor can put
+// them back. Calling it again while the cursor's hidden does nothing.
+func (hd *Hd44780I2c) HideCursor() error {
+	if hd.cursorHidden {
+		return nil
+	}
+	hd.hiddenCursor = hd.dMode & (lcdUnderlineCursorOn | lcdBlinkCursorOn)
+	hd.dMode &^= lcdUnderlineCursorOn | lcdBlinkCursorOn
+	err := hd.ApplyDisplayMode()
+	if err != nil {
+		return err
+	}
+	hd.cursorHidden = true
+	return nil
+}
+
+// ShowCursor turns the underline cursor and cursor blink back to how they were when HideCursor was called, it
+// does nothing if the cursor isn't hidden.
+func (hd *Hd44780I2c) ShowCursor() error {
+	if !hd.cursorHidden {
+		return nil
+	}
+	hd.dMode |= hd.hiddenCursor
+	err := hd.ApplyDisplayMode()
+	if err != nil {
+		return err
+	}
+	hd.cursorHidden = false
+	return nil
 }
 
 // EntryShiftOn sets entry shift on, moves all the text one space each time a letter is added.
 func (hd *Hd44780I2c) EntryShiftOn() error {
 	EntryShiftOn(hd)
-	return hd.setEntryMode()
+	return hd.ApplyEntryMode()
 }
 
 // EntryShiftOn sets entry shift off.
 func (hd *Hd44780I2c) EntryShiftOff() error {
 	EntryShiftOff(hd)
-	return hd.setEntryMode()
+	return hd.ApplyEntryMode()
+}
+
+// EnableTypeScroll sets the entry mode so the display scrolls as characters are typed, eg for input that's right
+// justified. The cursor stays in the same place on screen, each character is written there and everything that was
+// typed before it moves one cell along, left if leftward is true (entry increment) or right if it's false (entry
+// decrement). Set the cursor to where typing should happen first, the text that scrolls off one end comes back round
+// on the other once DDRAM wraps (after 80 characters on 1-line displays or 40 on 2-line displays). The tracked cursor
+// position (see Cursor) is in DDRAM so it moves on with each character even though the cursor doesn't move on
+// screen. Use EntryShiftOff to go back to normal entry.
+func (hd *Hd44780I2c) EnableTypeScroll(leftward bool) error {
+	if leftward {
+		EntryIncrement(hd)
+	} else {
+		EntryDecrement(hd)
+	}
+	EntryShiftOn(hd)
+	return hd.ApplyEntryMode()
 }
 
 // ShiftLeft shifts the cursor and all characters to the left.
@@ -463,39 +1151,129 @@ func (hd *Hd44780I2c) ShiftRight() error {
 	return hd.WriteInstruction(lcdCursorShift | lcdDisplayMove | lcdMoveRight)
 }
 
-// Home moves the cursor and all characters to the home position.
+// ShiftDisplay shifts the cursor and all characters n positions to the right, or to the left if right is false, eg
+// to scroll a banner that's longer than the display. The delay set with ShiftDelay is waited between each shift.
+func (hd *Hd44780I2c) ShiftDisplay(n int, right bool) error {
+	shift := hd.ShiftLeft
+	if right {
+		shift = hd.ShiftRight
+	}
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			time.Sleep(hd.shiftDelay)
+		}
+		err := shift()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CursorLeft moves the cursor one position to the left without shifting the display.
+func (hd *Hd44780I2c) CursorLeft() error {
+	err := hd.WriteInstruction(lcdCursorShift | lcdCursorMove | lcdMoveLeft)
+	if err != nil {
+		return err
+	}
+	hd.curCol--
+	return nil
+}
+
+// CursorRight moves the cursor one position to the right without shifting the display.
+func (hd *Hd44780I2c) CursorRight() error {
+	err := hd.WriteInstruction(lcdCursorShift | lcdCursorMove | lcdMoveRight)
+	if err != nil {
+		return err
+	}
+	hd.curCol++
+	return nil
+}
+
+// Home moves the cursor and all characters to the home position, it undoes any shifts. Like Clear it's one of the
+// slow instructions so it waits for the controller to finish, anything written before then would be lost.
 func (hd *Hd44780I2c) Home() error {
 	err := hd.WriteInstruction(lcdReturnHome)
-	return err
+	if err != nil {
+		return err
+	}
+	hd.curRow, hd.curCol, hd.ctrl = 0, 0, 0
+	return hd.waitReady(hd.timing.Home)
 }
 
-// Clear clears the display and mode settings sets the cursor to the home position.
+// Clear clears the display and sets the cursor to the home position. With DualController both controllers are
+// cleared at once.
 func (hd *Hd44780I2c) Clear() error {
 	err := hd.WriteInstruction(lcdClearDisplay)
 	if err != nil {
 		return err
 	}
-	time.Sleep(clearDelay)
-	// have to set mode here because clear also clears some mode settings
-	return hd.SetMode()
+	hd.curRow, hd.curCol, hd.ctrl = 0, 0, 0
+	hd.clearCells()
+	hd.clearDDRAM()
+	err = hd.waitReady(hd.timing.Clear)
+	if err != nil {
+		return err
+	}
+	// clear also sets entry increment mode (but leaves entry shift, display and function modes alone) so the entry
+	// mode has to be set again
+	return hd.ApplyEntryMode()
+}
+
+// Close clears the display then turns off both the display and the backlight. The I²C connection is only closed if
+// CloseBus is true (and the bus implements io.Closer).
+func (hd *Hd44780I2c) Close() error {
+	functions := []func() error{
+		hd.Clear,
+		hd.DisplayOff,
+		hd.BacklightOff,
+	}
+	for _, f := range functions {
+		err := f()
+		if err != nil {
+			return err
+		}
+	}
+
+	if c, ok := hd.bus.(io.Closer); ok && hd.CloseBus {
+		return c.Close()
+	}
+	return nil
 }
 
 // LoadCustomChars stores 8 custom characters into CGRAM, see type CustomChar docs for an example.
+// The cursor is put back where it was afterwards. There's only room for 4 custom characters in 5x10-pixel
+// character mode, where CGRAM is laid out as 4 characters of 16 bytes, so ErrTooManyCustomChars is returned without
+// writing anything, use SetCustomChar or Set5x10CustomChar instead.
 func (hd *Hd44780I2c) LoadCustomChars(chars [8]CustomChar) error {
-	err := hd.WriteInstruction(lcdSetCGRamAddr)
+	defer hd.startOp()()
+
+	if hd.Dots5x10Enabled() {
+		return fmt.Errorf("%w: 5x10-pixel character mode has 4 slots", ErrTooManyCustomChars)
+	}
+	for slot := range chars {
+		lines, err := hd.customCharLines(slot, chars[slot][:])
+		if err != nil {
+			return err
+		}
+		copy(chars[slot][:], lines)
+	}
+
+	err := hd.WriteInstruction(cgramAddr(0))
 	if err != nil {
 		return err
 	}
 
-	for _, c := range chars {
-		for _, b := range c {
-			err = hd.WriteChar(b)
+	// write rather than WriteChar so the cursor isn't moved
+	for slot, c := range chars {
+		for line, b := range c {
+			err = hd.write(b, registerSelectHigh)
 			if err != nil {
-				return err
+				return hd.customCharError(slot, line, err)
 			}
 		}
 	}
-	return nil
+	return hd.restoreDDRamAddr()
 }
 
 // DefaultModes are the default initialization modes for an HD44780.
@@ -527,6 +1305,23 @@ func EntryShiftOff(hd *Hd44780I2c) { hd.eMode &= ^lcdEntryShiftOn }
 // EntryShiftOn is a ModeSetter that sets the HD44780 to entry shift on mode.
 func EntryShiftOn(hd *Hd44780I2c) { hd.eMode |= lcdEntryShiftOn }
 
+// LeftToRight is a ModeSetter that makes text go from left to right, each character is written to the right of the
+// last. It's EntryIncrement, named as in the Arduino LiquidCrystal library, and it's the default.
+func LeftToRight(hd *Hd44780I2c) { EntryIncrement(hd) }
+
+// RightToLeft is a ModeSetter that makes text go from right to left, each character is written to the left of the
+// last. It's EntryDecrement.
+func RightToLeft(hd *Hd44780I2c) { EntryDecrement(hd) }
+
+// AutoScrollOn is a ModeSetter that makes the display shift with each character written so the cursor stays in the
+// same place on screen and the text scrolls away from it, left with LeftToRight and right with RightToLeft. It's
+// EntryShiftOn.
+func AutoScrollOn(hd *Hd44780I2c) { EntryShiftOn(hd) }
+
+// AutoScrollOff is a ModeSetter that stops the display shifting as characters are written, it's EntryShiftOff and
+// it's the default.
+func AutoScrollOff(hd *Hd44780I2c) { EntryShiftOff(hd) }
+
 // DisplayOff is a ModeSetter that sets the HD44780 to display off mode.
 func DisplayOff(hd *Hd44780I2c) { hd.dMode &= ^lcdDisplayOn }
 
@@ -548,7 +1343,8 @@ func BlinkCursorOn(hd *Hd44780I2c) { hd.dMode |= lcdBlinkCursorOn }
 // FourBitMode is a ModeSetter that sets the HD44780 to 4-bit bus mode.
 func FourBitMode(hd *Hd44780I2c) { hd.fMode &= ^lcd8BitMode }
 
-// EightBitMode is a ModeSetter that sets the HD44780 to 8-bit bus mode (only 4 bit mode valid with I²C).
+// EightBitMode is a ModeSetter that sets the HD44780 to 8-bit bus mode, this requires a 16-bit port expander with
+// D0 - D7 all wired (see I2CPinMap). It must be passed to the constructor as the init sequence differs between modes.
 func EightBitMode(hd *Hd44780I2c) { hd.fMode |= lcd8BitMode }
 
 // OneLine is a ModeSetter that sets the HD44780 to 1-line display mode.
@@ -563,6 +1359,142 @@ func Dots5x8(hd *Hd44780I2c) { hd.fMode &= ^lcd5x10Dots }
 // Dots5x10 is a ModeSetter that sets the HD44780 to 5x10-pixel character mode.
 func Dots5x10(hd *Hd44780I2c) { hd.fMode |= lcd5x10Dots }
 
+// SkipInit is a ModeSetter that stops the constructor from running the init sequence (which clears the display), use
+// it to attach to a display that's already been initialised, eg by a previous run of your program. The modes are
+// still sent but the driver assumes the display is in 4-bit mode (or 8-bit if EightBitMode is given) and in step with
+// it, if that's not the case (eg the display has been power cycled) it will show garbage until it's initialised again.
+// It only has an effect when passed to the constructor.
+func SkipInit(hd *Hd44780I2c) { hd.skipInit = true }
+
+// CheckConnection is a ModeSetter that makes the constructor check the display is really there, by writing a
+// pattern to CGRAM slot 0 and reading it back, an InitError with stage ErrNotResponding is returned if it doesn't
+// match. Without it a wrong address or unpowered display usually isn't noticed as many backpacks acknowledge writes
+// regardless. RW must be wired and the bus must implement io.Reader, otherwise ErrReadNotSupported is returned before
+// anything is sent. It only has an effect when passed to the constructor.
+func CheckConnection(hd *Hd44780I2c) { hd.checkConn = true }
+
+// Watchdog returns a ModeSetter that makes the display be re-initialised with Reinit when the given number of writes
+// in a row fail (after any retries, see RetryWrites), eg to recover from a power glitch on a display that's always
+// on. The write that failed is tried again afterwards, so the caller only sees an error if that fails too. Each
+// time it's triggered Logf is called. failures of 0 turns the watchdog off, the default.
+func Watchdog(failures int) ModeSetter {
+	return func(hd *Hd44780I2c) { hd.watchdog = failures }
+}
+
+// VerifyBusMode is a ModeSetter that makes the constructor check that the controller is in 4-bit (or 8-bit) bus mode
+// after the init sequence by setting the address counter and reading it back, so miswiring or a controller stuck in
+// the wrong mode is an error rather than garbage on the display. RW must be wired and the bus must implement
+// io.Reader.
+func VerifyBusMode(hd *Hd44780I2c) { hd.verifyBusMode = true }
+
+// NoInitialClear is a ModeSetter that stops the constructors clearing the display at the end of the init sequence,
+// eg to take over a display that should keep showing what's on it. The copy of DDRAM kept in software starts out
+// blank so it won't match what's shown until it's been rewritten.
+func NoInitialClear(hd *Hd44780I2c) { hd.noInitialClear = true }
+
+// InitFunc is a ModeSetter that replaces the init sequence the constructors send with f, for controllers that are
+// nearly compatible but need a different sequence, eg extra instructions or longer delays. Whatever f sends, the bus
+// mode is checked (with VerifyBusMode), the display cleared (unless NoInitialClear is set) and the modes set after
+// it returns as they are after the standard sequence. See StandardInit.
+func InitFunc(f func(hd *Hd44780I2c) error) ModeSetter {
+	return func(hd *Hd44780I2c) { hd.initFunc = f }
+}
+
+// ShiftDelay is a ModeSetter that sets how long ShiftDisplay waits between each shift, a scrolling banner is hard
+// to read if it moves faster than the liquid crystal responds (a few hundred milliseconds per character). The
+// default is not to wait.
+func ShiftDelay(d time.Duration) ModeSetter {
+	return func(hd *Hd44780I2c) { hd.shiftDelay = d }
+}
+
+// BacklightInitiallyOff is a ModeSetter that keeps the backlight off while the display is initialised, it's meant
+// for the constructor, use BacklightOn and BacklightOff after that.
+func BacklightInitiallyOff(hd *Hd44780I2c) { hd.backlight = false }
+
+// Dimensions returns a ModeSetter that sets the number of rows and columns on the display, it's used by the
+// functions that lay out text such as DisplayWrapped. Without it the size is guessed from the row addresses and line
+// mode, so it's only needed for 4 row displays or displays with nonstandard row addresses.
+func Dimensions(rows, cols byte) ModeSetter {
+	return func(hd *Hd44780I2c) {
+		hd.rows = rows
+		hd.cols = cols
+	}
+}
+
+// Geometry returns a ModeSetter that sets the size of the display from a string of columns x rows, eg "20x4" for
+// a 20 column 4 row display, so it can come from a config file or flag. It sets the row addresses (replacing the
+// ones passed to the constructor), the dimensions and 1 or 2-line mode. Displays up to 4 rows and 80 characters
+// are supported apart from 40x4, which has 2 controllers (see DualController). If geometry isn't valid the
+// constructor or SetMode returns an error wrapping ErrInvalidGeometry.
+func Geometry(geometry string) ModeSetter {
+	return func(hd *Hd44780I2c) {
+		var cols, rows int
+		var err error
+		size := strings.Split(strings.ToLower(strings.TrimSpace(geometry)), "x")
+		if len(size) == 2 {
+			cols, err = strconv.Atoi(size[0])
+			if err == nil {
+				rows, err = strconv.Atoi(size[1])
+			}
+		}
+		valid := len(size) == 2 && err == nil && cols > 0 && rows > 0 && rows <= 4 && cols*rows <= 80
+		if !valid || (rows > 2 && cols > 20) {
+			hd.modeErr = fmt.Errorf("%w: %q", ErrInvalidGeometry, geometry)
+			return
+		}
+
+		c := byte(cols)
+		// rows 3 and 4 carry on from the end of rows 1 and 2, eg 0x14 and 0x54 on a 20 column display
+		hd.RowAddr = RowAddress{0x00, 0x40, c, 0x40 + c}
+		hd.rows, hd.cols = byte(rows), c
+		if rows == 1 {
+			OneLine(hd)
+		} else {
+			TwoLine(hd)
+		}
+	}
+}
+
+// DualController returns a ModeSetter for 40x4 displays, which have 2 controllers that each drive 2 of the rows
+// and share everything but the EN pin. en2 is the pin the second controller's EN is connected to, PinMap.EN is
+// the first's. On a PCF8574 backpack there's no spare pin so RW is usually used (with the display's RW tied low),
+// then nothing can be read from the display: reads return ErrReadNotSupported and the constructor rejects
+// PollBusyFlag, VerifyBusMode and CheckConnection. Use it with RowAddress40x4.
+//
+// Characters and the DDRAM address go to the controller of the row the cursor is on, everything else (clearing,
+// modes, shifts and custom characters) goes to both. The cursor is shown by both controllers if it's on, the copy
+// of DDRAM kept in software (see SaveState) is kept for each controller.
+func DualController(en2 byte) ModeSetter {
+	return func(hd *Hd44780I2c) {
+		hd.dual, hd.en2 = true, en2
+		hd.rows, hd.cols = 4, 40
+	}
+}
+
+// StrictOverflow is a ModeSetter that makes writing past the end of a line return ErrOverflow rather than writing
+// into DDRAM that isn't shown. DisplayString checks before writing anything, methods that write at the cursor return
+// the error at the first character that doesn't fit. It doesn't apply with line wrapping (see LineWrapToTop and
+// LineWrapScroll) or EntryShiftOn, where characters past the end of the line are shown.
+func StrictOverflow(hd *Hd44780I2c) { hd.strictWidth = true }
+
+// LenientOverflow is a ModeSetter that lets characters be written past the end of a line into DDRAM that isn't
+// shown, as the controller does. It's the default.
+func LenientOverflow(hd *Hd44780I2c) { hd.strictWidth = false }
+
+// overflowChecked reports whether writing past the end of a line returns ErrOverflow.
+func (hd *Hd44780I2c) overflowChecked() bool {
+	return hd.strictWidth && hd.wrap == wrapOff && !hd.EntryShiftEnabled()
+}
+
+// Split16x1 is a ModeSetter for 16x1 displays that are really 8x2 inside, the left 8 characters are at DDRAM
+// addresses 0x00 - 0x07 and the right 8 at 0x40 - 0x47. Text is written across the whole line as if it were a
+// normal 16x1 display, the address is set when it crosses from one half to the other. It sets 2-line mode, which
+// these displays need, so don't use OneLine with it.
+func Split16x1(hd *Hd44780I2c) {
+	hd.fMode |= lcd2Line
+	hd.rows, hd.cols, hd.splitAt = 1, 16, 8
+}
+
 // EntryIncrementEnabled returns true if entry increment mode is enabled.
 func (hd *Hd44780I2c) EntryIncrementEnabled() bool { return hd.eMode&lcdEntryIncrement > 0 }
 
@@ -586,9 +1518,49 @@ func (hd *Hd44780I2c) EightBitModeEnabled() bool { return hd.fMode&lcd8BitMode >
 // display mode is enabled.
 func (hd *Hd44780I2c) TwoLineEnabled() bool { return hd.fMode&lcd2Line > 0 }
 
+// Dots5x10Enabled returns true if 5x10-pixel character mode is enabled and false if 5x8-pixel
+// character mode is enabled.
+func (hd *Hd44780I2c) Dots5x10Enabled() bool { return hd.fMode&lcd5x10Dots > 0 }
+
+// Size returns the number of rows and columns on the display, as set with Dimensions, Geometry or one of the
+// ModeSetters for a particular display, or guessed from the row addresses and line mode, so layouts can adapt to it.
+func (hd *Hd44780I2c) Size() (rows, cols byte) { return hd.rows, hd.cols }
+
+// String returns the state of the mode flags and backlight, eg for a bug report.
+func (hd *Hd44780I2c) String() string {
+	onOff := func(b bool) string {
+		if b {
+			return "on"
+		}
+		return "off"
+	}
+	bus, lines, dots := "4-bit", 1, "5x8"
+	if hd.EightBitModeEnabled() {
+		bus = "8-bit"
+	}
+	if hd.TwoLineEnabled() {
+		lines = 2
+	}
+	if hd.Dots5x10Enabled() {
+		dots = "5x10"
+	}
+	return fmt.Sprintf(
+		"display:%s cursor:%s blink:%s increment:%s shift:%s bus:%s lines:%d dots:%s backlight:%s",
+		onOff(hd.DisplayEnabled()), onOff(hd.CursorEnabled()), onOff(hd.BlinkEnabled()),
+		onOff(hd.EntryIncrementEnabled()), onOff(hd.EntryShiftEnabled()), bus, lines, dots, onOff(hd.backlight),
+	)
+}
+
 func maxInt(a, b int) int {
 	if a > b {
 		return a
 	}
 	return b
 }
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,899 @@
+package hd44780
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClear(t *testing.T) {
+	hd, bus := newTestDisplay(t, EntryDecrement, EntryShiftOn)
+
+	err := hd.Clear()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// clear sets increment, only the entry mode needs sending again
+	want := []instruction{
+		{registerSelectLow, lcdClearDisplay},
+		{registerSelectLow, byte(lcdSetEntryMode | lcdEntryDecrement | lcdEntryShiftOn)},
+	}
+	got := bus.instructions(PCF8574PinMap)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got instructions %#v, want %#v", got, want)
+	}
+}
+
+func TestAddress(t *testing.T) {
+	tests := []struct {
+		name      string
+		modes     []ModeSetter
+		line, pos byte
+		address   byte
+		err       error
+	}{
+		{"2-line first line", []ModeSetter{TwoLine}, 0, 3, 0x03, nil},
+		{"2-line second line", []ModeSetter{TwoLine}, 1, 3, 0x43, nil},
+		{"2-line third line of 2", []ModeSetter{TwoLine}, 2, 0, 0, ErrInvalidLine},
+		{"2-line fourth line", []ModeSetter{TwoLine, Dimensions(4, 16)}, 3, 3, 0x53, nil},
+		{"2-line fifth line", []ModeSetter{TwoLine, Dimensions(4, 16)}, 4, 0, 0, ErrInvalidLine},
+		{"2-line past last column", []ModeSetter{TwoLine}, 0, 16, 0, ErrInvalidPos},
+		{"1-line", []ModeSetter{OneLine}, 0, 3, 0x03, nil},
+		{"1-line second line", []ModeSetter{OneLine}, 1, 0, 0, ErrInvalidLine},
+		{"1-line 40 columns", []ModeSetter{OneLine, Dimensions(1, 40)}, 0, 39, 0x27, nil},
+	}
+
+	for _, tt := range tests {
+		hd, _ := newTestDisplay(t, tt.modes...)
+
+		address, _, err := hd.address(tt.line, tt.pos)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, tt.err)
+		}
+		if address != tt.address {
+			t.Errorf("%s: got address %#02x, want %#02x", tt.name, address, tt.address)
+		}
+	}
+}
+
+func TestResync(t *testing.T) {
+	hd, bus := newTestDisplay(t)
+	err := hd.SetCursor(1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus.written = nil
+
+	err = hd.Resync()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the 4 single nibbles 0x3, 0x3, 0x3, 0x2 are decoded in pairs
+	want := []instruction{
+		{registerSelectLow, 0x33},
+		{registerSelectLow, 0x32},
+		{registerSelectLow, byte(lcdSetFunctionMode | lcd2Line)},
+		{registerSelectLow, lcdSetDDRamAddr | 0x42},
+	}
+	got := bus.instructions(hd.PinMap)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got instructions %#v, want %#v", got, want)
+	}
+}
+
+func TestBacklightBit(t *testing.T) {
+	tests := []struct {
+		name      string
+		pinMap    I2CPinMap
+		backlight bool
+		want      byte
+	}{
+		{"positive on", PCF8574PinMap, true, 0x08},
+		{"positive off", PCF8574PinMap, false, 0x00},
+		{"negative on", MJKDZPinMap, true, 0x00},
+		{"negative off", MJKDZPinMap, false, 0x80},
+	}
+
+	for _, tt := range tests {
+		bus := &fakeBus{}
+		hd, err := NewHd44780(bus, tt.pinMap, RowAddress16Col, SkipInit)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		bus.written = nil
+		if tt.backlight {
+			err = hd.BacklightOn()
+		} else {
+			err = hd.BacklightOff()
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(bus.written, []byte{tt.want}) {
+			t.Errorf("%s: got %#v, want %#v", tt.name, bus.written, []byte{tt.want})
+		}
+
+		// every byte of a write keeps the backlight as it is
+		bus.written = nil
+		err = hd.WriteChar('A')
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, b := range bus.written {
+			if b&(0x01<<tt.pinMap.Backlight) != tt.want {
+				t.Errorf("%s: got %#02x written for a character, want backlight bit %#02x", tt.name, b, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestSetModeOnlySendsChanges(t *testing.T) {
+	hd, bus := newTestDisplay(t)
+
+	err := hd.SetMode(BlinkCursorOn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = hd.SetMode(BlinkCursorOn, TwoLine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = hd.Clear()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []instruction{
+		{registerSelectLow, byte(lcdSetDisplayMode | lcdDisplayOn | lcdBlinkCursorOn)},
+		{registerSelectLow, lcdClearDisplay},
+		{registerSelectLow, byte(lcdSetEntryMode | lcdEntryIncrement)},
+	}
+	got := bus.instructions(hd.PinMap)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got instructions %#v, want %#v", got, want)
+	}
+}
+
+func TestReadDDRAMNotSupported(t *testing.T) {
+	hd, bus := newTestDisplay(t)
+
+	_, err := hd.ReadDDRAM(0x00, 16)
+	if !errors.Is(err, ErrReadNotSupported) {
+		t.Errorf("got %v, want %v", err, ErrReadNotSupported)
+	}
+	if len(bus.written) > 0 {
+		t.Errorf("got %#v written, want nothing", bus.written)
+	}
+}
+
+func TestOnWrite(t *testing.T) {
+	hd, _ := newTestDisplay(t)
+	var got []instruction
+	hd.OnWrite = func(data byte, rs RegisterSelect) {
+		got = append(got, instruction{rs, data})
+	}
+
+	err := hd.DisplayString("ab", 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []instruction{
+		{InstructionRegister, lcdSetDDRamAddr | 0x40},
+		{DataRegister, 'a'},
+		{DataRegister, 'b'},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestOnNibble(t *testing.T) {
+	hd, _ := newTestDisplay(t)
+	type nibble struct {
+		nibble byte
+		rs     RegisterSelect
+		high   bool
+	}
+	var got []nibble
+	hd.OnNibble = func(n byte, rs RegisterSelect, high bool) {
+		got = append(got, nibble{n, rs, high})
+	}
+
+	err := hd.WriteChar('a')
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []nibble{{0x6, DataRegister, true}, {0x1, DataRegister, false}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestCustomCharHighBits(t *testing.T) {
+	c := CustomChar{0xff, 0x1f, 0x20, 0x00, 0x00, 0x00, 0x00, 0x00}
+
+	hd, bus := newTestDisplay(t)
+	err := hd.SetCustomChar(1, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []byte
+	for _, ins := range bus.instructions(hd.PinMap) {
+		if ins.rs == registerSelectHigh {
+			got = append(got, ins.data)
+		}
+	}
+	want := []byte{0x1f, 0x1f, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got lines %#v written, want %#v", got, want)
+	}
+
+	hd, bus = newTestDisplay(t, StrictCustomChars)
+	err = hd.SetCustomChar(1, c)
+	if !errors.Is(err, ErrInvalidCustomChar) {
+		t.Errorf("got %v, want %v", err, ErrInvalidCustomChar)
+	}
+	set, err := NewCustomCharSet(CustomChar{}, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = hd.LoadCustomChars(set)
+	if !errors.Is(err, ErrInvalidCustomChar) {
+		t.Errorf("got %v loading all custom characters, want %v", err, ErrInvalidCustomChar)
+	}
+	if len(bus.written) > 0 {
+		t.Errorf("got %#v written, want nothing", bus.written)
+	}
+}
+
+func TestSplit16x1(t *testing.T) {
+	hd, bus := newTestDisplay(t, Split16x1)
+
+	err := hd.DisplayString("sixteenchars!!!!", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want []instruction
+	want = append(want, instruction{registerSelectLow, lcdSetDDRamAddr | 0x00})
+	for _, c := range []byte("sixteenc") {
+		want = append(want, instruction{registerSelectHigh, c})
+	}
+	want = append(want, instruction{registerSelectLow, lcdSetDDRamAddr | 0x40})
+	for _, c := range []byte("hars!!!!") {
+		want = append(want, instruction{registerSelectHigh, c})
+	}
+	got := bus.instructions(hd.PinMap)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got instructions %#v, want %#v", got, want)
+	}
+
+	address, _, err := hd.address(0, 12)
+	if err != nil || address != 0x44 {
+		t.Errorf("got address %#02x, %v for column 12, want 0x44", address, err)
+	}
+	_, _, err = hd.address(1, 0)
+	if !errors.Is(err, ErrInvalidLine) {
+		t.Errorf("got %v for line 1, want %v", err, ErrInvalidLine)
+	}
+	if row, col := hd.Cursor(); row != 0 || col != 16 {
+		t.Errorf("got cursor at %d, %d, want 0, 16", row, col)
+	}
+}
+
+func TestClearDualController(t *testing.T) {
+	// RW is used as the second controller's EN
+	bus := &fakeBus{}
+	hd, err := NewHd44780(bus, PCF8574PinMap, RowAddress40x4, SkipInit, DualController(PCF8574PinMap.RW))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = hd.DisplayString("x", 3, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus.written = nil
+
+	err = hd.Clear()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// both controllers get the clear (and the entry mode after it)
+	want := []instruction{
+		{registerSelectLow, lcdClearDisplay},
+		{registerSelectLow, byte(lcdSetEntryMode | lcdEntryIncrement)},
+	}
+	second := PCF8574PinMap
+	second.EN = PCF8574PinMap.RW
+	for _, pm := range []I2CPinMap{PCF8574PinMap, second} {
+		got := bus.instructions(pm)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("EN on pin %d: got instructions %#v, want %#v", pm.EN, got, want)
+		}
+	}
+	if row, col := hd.Cursor(); row != 0 || col != 0 || hd.ctrl != 0 {
+		t.Errorf("got cursor at %d, %d on controller %d, want 0, 0 on controller 0", row, col, hd.ctrl)
+	}
+}
+
+func TestDualControllerCursor(t *testing.T) {
+	hd, err := NewHd44780(&fakeBus{}, PCF8574PinMap, RowAddress40x4, SkipInit, DualController(PCF8574PinMap.RW))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = hd.SetCursor(3, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// positions that are only checked, or aren't valid, don't move the cursor to the other controller
+	_, err = hd.DisplayStringN("abc", 0, 40)
+	if !errors.Is(err, ErrInvalidPos) {
+		t.Errorf("got %v, want ErrInvalidPos", err)
+	}
+	if hd.ctrl != 1 {
+		t.Errorf("got controller %d after an invalid position, want 1", hd.ctrl)
+	}
+
+	err = hd.SetDDRamAddr(0x45)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row, col := hd.Cursor(); row != 3 || col != 5 {
+		t.Errorf("got cursor at %d, %d for 0x45 on the second controller, want 3, 5", row, col)
+	}
+}
+
+func TestCustomCharBetweenChars(t *testing.T) {
+	hd, bus := newTestDisplay(t)
+
+	err := hd.SetCursor(1, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = hd.WriteChar('a')
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = hd.SetCustomChar(2, CustomChar{0x1f})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = hd.WriteChar('b')
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the address is set back to where the next character goes after the custom character is written
+	ins := bus.instructions(hd.PinMap)
+	want := []instruction{
+		{registerSelectLow, lcdSetDDRamAddr | 0x45},
+		{registerSelectHigh, 'b'},
+	}
+	if got := ins[len(ins)-2:]; !reflect.DeepEqual(got, want) {
+		t.Errorf("got instructions %#v at the end, want %#v", got, want)
+	}
+	if got := string(hd.ddram[0][0x44:0x46]); got != "ab" {
+		t.Errorf("got %q in DDRAM, want %q", got, "ab")
+	}
+}
+
+func TestSpinnerRestoresCell(t *testing.T) {
+	hd, bus := newTestDisplay(t)
+	err := hd.DisplayString("abcdef", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus.written = nil
+
+	// the first frame is drawn before the cancelled context is noticed
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = hd.Spinner(0, 2, time.Hour, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []instruction{
+		{registerSelectLow, lcdSetDDRamAddr | 0x02},
+		{registerSelectHigh, '|'},
+		{registerSelectLow, lcdSetDDRamAddr | 0x06},
+		{registerSelectLow, lcdSetDDRamAddr | 0x02},
+		{registerSelectHigh, 'c'},
+		{registerSelectLow, lcdSetDDRamAddr | 0x06},
+	}
+	got := bus.instructions(hd.PinMap)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got instructions %#v, want %#v", got, want)
+	}
+	if row, col := hd.Cursor(); row != 0 || col != 6 {
+		t.Errorf("got cursor at %d, %d, want 0, 6", row, col)
+	}
+}
+
+func TestPinMapValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		edit  func(pm *I2CPinMap)
+		valid bool
+	}{
+		{"pcf8574", func(pm *I2CPinMap) {}, true},
+		{"out of range", func(pm *I2CPinMap) { pm.D7 = 8 }, false},
+		{"shared", func(pm *I2CPinMap) { pm.Backlight = pm.EN }, false},
+		// D0 - D3 aren't used in 4-bit mode
+		{"unused pins", func(pm *I2CPinMap) { pm.D0 = 20 }, true},
+	}
+	for _, tt := range tests {
+		pm := PCF8574PinMap
+		tt.edit(&pm)
+		err := pm.Validate()
+		if tt.valid && err != nil {
+			t.Errorf("%s: got %v, want no error", tt.name, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidPinMap) {
+			t.Errorf("%s: got %v, want ErrInvalidPinMap", tt.name, err)
+		}
+	}
+
+	bad := PCF8574PinMap
+	bad.D4 = bad.RS
+	bus := &fakeBus{}
+	_, err := NewHd44780(bus, bad, RowAddress16Col)
+	if !errors.Is(err, ErrInvalidPinMap) {
+		t.Errorf("got %v from the constructor, want ErrInvalidPinMap", err)
+	}
+	if len(bus.written) > 0 {
+		t.Error("the constructor wrote to the bus with an invalid pin map")
+	}
+}
+
+func TestRotate180(t *testing.T) {
+	hd, _ := newTestDisplay(t, Rotate180)
+
+	// 'a' doesn't have an upside down equivalent so it's written as it is
+	err := hd.DisplayString("6ba", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(hd.ddram[0][0x4d:0x50]); got != "aq9" {
+		t.Errorf("got %q at the end of the bottom line, want %q", got, "aq9")
+	}
+
+	// text that goes past the reader's right edge is cut off at the display's left edge
+	err = hd.DisplayString("bbb", 1, 14)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(hd.ddram[0][0x00:0x03]); got != "qq " {
+		t.Errorf("got %q at the start of the top line, want %q", got, "qq ")
+	}
+}
+
+func TestBlitRow(t *testing.T) {
+	hd, bus := newTestDisplay(t)
+
+	err := hd.BlitRow(1, []byte("0123456789abcdefXYZ"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := bus.instructions(hd.PinMap)
+	if len(got) != 17 || got[0] != (instruction{registerSelectLow, lcdSetDDRamAddr | 0x40}) {
+		t.Fatalf("got instructions %#v, want the address of line 1 and 16 characters", got)
+	}
+	if got := string(hd.ddram[0][0x40:0x50]); got != "0123456789abcdef" {
+		t.Errorf("got %q on line 1, want %q", got, "0123456789abcdef")
+	}
+
+	err = hd.BlitRow(2, []byte("x"))
+	if !errors.Is(err, ErrInvalidLine) {
+		t.Errorf("got %v for line 2, want ErrInvalidLine", err)
+	}
+}
+
+func TestLoadCustomCharsDotMode(t *testing.T) {
+	hd, bus := newTestDisplay(t)
+	var chars [8]CustomChar
+	chars[0] = CustomChar{0x1f}
+
+	err := hd.SetMode(Dots5x10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus.written = nil
+	err = hd.LoadCustomChars(chars)
+	if !errors.Is(err, ErrTooManyCustomChars) {
+		t.Errorf("got %v in 5x10-pixel mode, want ErrTooManyCustomChars", err)
+	}
+	if len(bus.written) > 0 {
+		t.Error("CGRAM was written in 5x10-pixel mode")
+	}
+
+	err = hd.SetMode(Dots5x8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus.written = nil
+	err = hd.LoadCustomChars(chars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := bus.instructions(hd.PinMap)
+	// the CGRAM address, 64 lines and the DDRAM address to put the cursor back
+	if len(got) != 66 || got[0] != (instruction{registerSelectLow, lcdSetCGRamAddr}) || got[1].data != 0x1f {
+		t.Errorf("got instructions %#v, want all 8 characters loaded", got)
+	}
+}
+
+func TestCustomChars5x10(t *testing.T) {
+	hd, bus := newTestDisplay(t, Dots5x10)
+	a, b, c := CustomChar{0x01}, CustomChar{0x02}, CustomChar{0x03}
+
+	err := hd.DrawBitmap([][]CustomChar{{a, b, c, a}}, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// each slot is 16 bytes of CGRAM and is shown by twice its number
+	got := bus.instructions(hd.PinMap)
+	var cgram []byte
+	for _, ins := range got {
+		if ins.rs == registerSelectLow && ins.data&0xc0 == lcdSetCGRamAddr {
+			cgram = append(cgram, ins.data)
+		}
+	}
+	if want := []byte{0x40, 0x50, 0x60}; !reflect.DeepEqual(cgram, want) {
+		t.Errorf("got CGRAM addresses %#v, want %#v", cgram, want)
+	}
+	want := []instruction{
+		{registerSelectLow, lcdSetDDRamAddr | 0x42},
+		{registerSelectHigh, 0x00},
+		{registerSelectHigh, 0x02},
+		{registerSelectHigh, 0x04},
+		{registerSelectHigh, 0x00},
+	}
+	if len(got) < len(want) || !reflect.DeepEqual(got[len(got)-len(want):], want) {
+		t.Errorf("got instructions %#v, want them to end with %#v", got, want)
+	}
+	if !bytes.Equal(hd.ddram[0][0x42:0x46], []byte{0x00, 0x02, 0x04, 0x00}) {
+		t.Errorf("got DDRAM %#v", hd.ddram[0][0x42:0x46])
+	}
+
+	err = hd.SetMode(Dots5x8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = hd.Set5x10CustomChar(0, CustomChar5x10{})
+	if !errors.Is(err, ErrDotMode) {
+		t.Errorf("got %v from Set5x10CustomChar in 5x8-pixel mode, want ErrDotMode", err)
+	}
+}
+
+func TestStrictOverflow(t *testing.T) {
+	hd, bus := newTestDisplay(t, StrictOverflow)
+
+	err := hd.DisplayString("too long", 0, 10)
+	if !errors.Is(err, ErrOverflow) {
+		t.Errorf("got %v from DisplayString, want ErrOverflow", err)
+	}
+	if len(bus.written) > 0 {
+		t.Error("DisplayString wrote text that doesn't fit")
+	}
+
+	err = hd.DisplayString("fits", 0, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := hd.Write([]byte("x"))
+	if !errors.Is(err, ErrOverflow) || n != 0 {
+		t.Errorf("got %d, %v from Write at the end of the line, want 0, ErrOverflow", n, err)
+	}
+
+	err = hd.SetMode(LenientOverflow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = hd.Write([]byte("x"))
+	if err != nil {
+		t.Errorf("got %v from Write with LenientOverflow, want no error", err)
+	}
+
+	// in entry decrement mode the text runs left from pos
+	hd, bus = newTestDisplay(t, StrictOverflow, EntryDecrement)
+	err = hd.DisplayString("ab", 0, 15)
+	if err != nil {
+		t.Errorf("got %v for text that fits leftwards, want no error", err)
+	}
+	bus.written = nil
+	err = hd.DisplayString("abc", 0, 1)
+	if !errors.Is(err, ErrOverflow) {
+		t.Errorf("got %v from DisplayString past the first column, want ErrOverflow", err)
+	}
+	if len(bus.written) > 0 {
+		t.Error("DisplayString wrote text that doesn't fit leftwards")
+	}
+}
+
+func TestGeometry(t *testing.T) {
+	hd, _ := newTestDisplay(t, Geometry("20x4"))
+	if rows, cols := hd.Size(); rows != 4 || cols != 20 || hd.RowAddr != RowAddress20Col || !hd.TwoLineEnabled() {
+		t.Errorf("got %dx%d with row addresses %#v, want 20x4 with RowAddress20Col in 2-line mode", cols, rows,
+			hd.RowAddr)
+	}
+
+	for _, g := range []string{"20x", "20x4x1", "40x4", "0x2", "16 x 2"} {
+		_, err := NewHd44780(&fakeBus{}, PCF8574PinMap, RowAddress16Col, SkipInit, Geometry(g))
+		if !errors.Is(err, ErrInvalidGeometry) {
+			t.Errorf("%q: got %v, want ErrInvalidGeometry", g, err)
+		}
+	}
+}
+
+func TestNoBacklightPin(t *testing.T) {
+	pm := PCF8574PinMap
+	pm.Backlight = NoPin
+	bus := &fakeBus{}
+	hd, err := NewHd44780(bus, pm, RowAddress16Col, SkipInit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus.written = nil
+
+	err = hd.BacklightOff()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bus.written) > 0 {
+		t.Errorf("got %#v written by BacklightOff, want nothing", bus.written)
+	}
+	err = hd.WriteChar('a')
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range bus.written {
+		if b&(0x01<<PCF8574PinMap.Backlight) > 0 {
+			t.Fatalf("got %#02x written, want the old backlight pin left low", b)
+		}
+	}
+}
+
+func TestOnCell(t *testing.T) {
+	hd, _ := newTestDisplay(t)
+	type cell struct{ row, col, code byte }
+	var got []cell
+	hd.OnCell = func(row, col, code byte) { got = append(got, cell{row, col, code}) }
+
+	// the unchanged 'a' and the character past the end of the line aren't reported
+	err := hd.DisplayString("ab", 1, 14)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = hd.DisplayString("acx", 1, 14)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = hd.Clear()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []cell{{1, 14, 'a'}, {1, 15, 'b'}, {1, 15, 'c'}, {1, 14, ' '}, {1, 15, ' '}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got cells %v, want %v", got, want)
+	}
+}
+
+func TestCGRAMAddr(t *testing.T) {
+	want := []byte{0x40, 0x48, 0x50, 0x58, 0x60, 0x68, 0x70, 0x78}
+	for slot, addr := range want {
+		if got := cgramAddr(byte(slot)); got != addr {
+			t.Errorf("slot %d: got %#02x, want %#02x", slot, got, addr)
+		}
+	}
+}
+
+func TestEnableTypeScroll(t *testing.T) {
+	for _, tt := range []struct {
+		leftward bool
+		entry    byte
+	}{
+		{true, byte(lcdSetEntryMode | lcdEntryIncrement | lcdEntryShiftOn)},
+		{false, byte(lcdSetEntryMode | lcdEntryDecrement | lcdEntryShiftOn)},
+	} {
+		hd, bus := newTestDisplay(t)
+		err := hd.EnableTypeScroll(tt.leftward)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []instruction{{registerSelectLow, tt.entry}}
+		got := bus.instructions(hd.PinMap)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("leftward %v: got instructions %#v, want %#v", tt.leftward, got, want)
+		}
+		if !hd.EntryShiftEnabled() || hd.EntryIncrementEnabled() != tt.leftward {
+			t.Errorf("leftward %v: got shift %v and increment %v", tt.leftward, hd.EntryShiftEnabled(),
+				hd.EntryIncrementEnabled())
+		}
+	}
+}
+
+func TestEnablePulse(t *testing.T) {
+	timing := DefaultTiming
+	timing.EnableHigh = 5 * time.Millisecond
+	hd, bus := newTestDisplay(t, SetTiming(timing))
+
+	start := time.Now()
+	err := hd.writeNibble(0xa, true, registerSelectHigh, 0x01<<hd.PinMap.EN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < timing.EnableHigh {
+		t.Errorf("took %v, want EN held high for at least %v", elapsed, timing.EnableHigh)
+	}
+
+	// D7 and D5 for 0xa, RS and the backlight, then EN
+	want := []byte{0xa9, 0xad, 0xa9}
+	if !reflect.DeepEqual(bus.written, want) {
+		t.Errorf("got %#v written, want %#v", bus.written, want)
+	}
+
+	hd.timing.EnableHigh = 0
+	if got := hd.enableHigh(); got != minEnableHigh {
+		t.Errorf("got EN high for %v with EnableHigh 0, want the minimum %v", got, minEnableHigh)
+	}
+}
+
+func BenchmarkWriteChar(b *testing.B) {
+	for _, enableHigh := range []time.Duration{0, pulseDelay, 10 * time.Microsecond} {
+		b.Run(enableHigh.String(), func(b *testing.B) {
+			timing := DefaultTiming
+			timing.EnableHigh = enableHigh
+			hd, err := NewHd44780(&fakeBus{}, PCF8574PinMap, RowAddress16Col, SkipInit, SetTiming(timing))
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				err = hd.WriteChar('a')
+				if err != nil {
+					b.Fatal(err)
+				}
+				// keep the cursor on the display
+				hd.curCol = 0
+			}
+		})
+	}
+}
+
+func TestWatchdog(t *testing.T) {
+	hd, bus := newTestDisplay(t, Watchdog(2))
+	var logged []string
+	hd.Logf = func(format string, args ...interface{}) { logged = append(logged, fmt.Sprintf(format, args...)) }
+	err := hd.DisplayString("ab", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first failure is returned, the second triggers the watchdog and the write is tried again
+	bus.failures = 1
+	err = hd.WriteChar('c')
+	if err == nil {
+		t.Fatal("got no error from the first failed write")
+	}
+	bus.failures = 1
+	err = hd.WriteChar('c')
+	if err != nil {
+		t.Fatalf("got %v, want the write to succeed after re-initialising", err)
+	}
+	if len(logged) != 1 {
+		t.Errorf("got %q logged, want 1 message", logged)
+	}
+	if got := string(hd.ddram[0][:3]); got != "abc" {
+		t.Errorf("got %q displayed, want %q", got, "abc")
+	}
+	if row, col := hd.Cursor(); row != 0 || col != 3 {
+		t.Errorf("got cursor at %d, %d, want 0, 3", row, col)
+	}
+}
+
+func TestInitFunc(t *testing.T) {
+	standard := &fakeBus{}
+	_, err := NewHd44780(standard, PCF8574PinMap, RowAddress16Col)
+	if err != nil {
+		t.Fatal(err)
+	}
+	custom := &fakeBus{}
+	hd, err := NewHd44780(custom, PCF8574PinMap, RowAddress16Col, InitFunc(func(hd *Hd44780I2c) error {
+		return hd.WriteInstruction(0x33)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// f replaces the init sequence, the clear and the modes are still sent after it
+	clearAt := func(ins []instruction) int {
+		for i, in := range ins {
+			if in == (instruction{registerSelectLow, lcdClearDisplay}) {
+				return i
+			}
+		}
+		t.Fatalf("the display wasn't cleared, got %#v", ins)
+		return 0
+	}
+	want, got := standard.instructions(PCF8574PinMap), custom.instructions(PCF8574PinMap)
+	ws, gs := clearAt(want), clearAt(got)
+	if !reflect.DeepEqual(got[:gs], []instruction{{registerSelectLow, 0x33}}) {
+		t.Errorf("got %#v before the clear, want just the init function's instruction", got[:gs])
+	}
+	if !reflect.DeepEqual(got[gs:], want[ws:]) {
+		t.Errorf("got %#v from the clear, want %#v as after the standard init", got[gs:], want[ws:])
+	}
+
+	// init changes the mode registers so Reinit sends them all again, not just the ones that have changed
+	custom.written = nil
+	err = hd.Reinit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = custom.instructions(PCF8574PinMap)
+	for _, mode := range []byte{hd.sentFunction, hd.sentDisplay} {
+		found := false
+		for _, in := range got {
+			found = found || in == instruction{registerSelectLow, mode}
+		}
+		if !found {
+			t.Errorf("mode instruction %#02x wasn't sent again by Reinit, got %#v", mode, got)
+		}
+	}
+}
+
+func TestHideCursor(t *testing.T) {
+	off := byte(lcdSetDisplayMode | lcdDisplayOn)
+	underline, blink := byte(lcdUnderlineCursorOn), byte(lcdBlinkCursorOn)
+
+	tests := []struct {
+		name  string
+		modes []ModeSetter
+		calls string // h for HideCursor, s for ShowCursor
+		want  []byte
+	}{
+		{"both", []ModeSetter{UnderlineCursorOn, BlinkCursorOn}, "hs", []byte{off, off | underline | blink}},
+		{"underline only", []ModeSetter{UnderlineCursorOn, BlinkCursorOff}, "hs", []byte{off, off | underline}},
+		{"blink only", []ModeSetter{UnderlineCursorOff, BlinkCursorOn}, "hs", []byte{off, off | blink}},
+		{"hide twice", []ModeSetter{UnderlineCursorOn, BlinkCursorOn}, "hhs", []byte{off, off | underline | blink}},
+		{"show twice", []ModeSetter{UnderlineCursorOn}, "hss", []byte{off, off | underline}},
+		{"show without hide", []ModeSetter{BlinkCursorOn}, "s", nil},
+	}
+	for _, tt := range tests {
+		hd, bus := newTestDisplay(t, tt.modes...)
+		for _, c := range tt.calls {
+			var err error
+			if c == 'h' {
+				err = hd.HideCursor()
+			} else {
+				err = hd.ShowCursor()
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		var want []instruction
+		for _, d := range tt.want {
+			want = append(want, instruction{registerSelectLow, d})
+		}
+		if got := bus.instructions(hd.PinMap); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got instructions %#v, want %#v", tt.name, got, want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package hd44780_test
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -56,15 +57,13 @@ func Example() {
 	time.Sleep(time.Second)
 	lcd.Clear()
 
-	s := "write chars"
-	for _, c := range s {
-		lcd.WriteChar(byte(c))
-		time.Sleep(time.Millisecond * 500)
-	}
+	lcd.TypeString("write chars", 0, 0, time.Millisecond*500, context.Background())
 	time.Sleep(time.Second)
 	lcd.Clear()
 
-	lcd.DisplayString(fmt.Sprintf("all chars, 30%bC", 223), 0, 0)
+	lcd.DisplayString("all chars, 30", 0, 0)
+	lcd.WriteGlyph(hd44780.DegreeSign, 0, 13)
+	lcd.DisplayString("C", 0, 14)
 	time.Sleep(time.Second)
 	lcd.Clear()
 
@@ -84,13 +83,18 @@ func Example() {
 	lcd.Clear()
 	lcd.Home()
 
-	lcd.DisplayString(fmt.Sprintf("%c%c%c%c%c%c%c", 0, 1, 2, 3, 4, 5, 6), 0, 0)
+	lcd.DisplayBytes([]byte{0, 1, 2, 3, 4, 5, 6}, 0, 0)
 	time.Sleep(time.Second * 3)
 
 	lcd.Clear()
 
-	for i := 0; i < 70; i++ {
-		lcd.DisplayString(fmt.Sprintf("%c", i%7), 0, 0)
-		time.Sleep(time.Millisecond * 500)
+	// cycle through the custom chars for 35 seconds
+	frames := make([]func() error, 7)
+	for i := range frames {
+		c := i
+		frames[i] = func() error { return lcd.DisplayString(fmt.Sprintf("%c", c), 0, 0) }
 	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*35)
+	defer cancel()
+	hd44780.Animate(frames, time.Millisecond*500, ctx)
 }
@@ -0,0 +1,145 @@
+package hd44780
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// katakanaCodes maps full-width katakana and Japanese punctuation to the half-width katakana in the A00 character
+// ROM at 0xa1 - 0xdf. Voiced (eg ガ) and semi-voiced (eg パ) katakana don't have codes of their own, they're the
+// plain katakana followed by the dakuten 0xde or handakuten 0xdf.
+var katakanaCodes = make(map[rune][]byte)
+
+// romajiKatakana maps Hepburn (and some Kunrei) romaji syllables to katakana.
+var romajiKatakana = map[string]string{
+	"a": "ア", "i": "イ", "u": "ウ", "e": "エ", "o": "オ",
+	"ka": "カ", "ki": "キ", "ku": "ク", "ke": "ケ", "ko": "コ",
+	"sa": "サ", "shi": "シ", "si": "シ", "su": "ス", "se": "セ", "so": "ソ",
+	"ta": "タ", "chi": "チ", "ti": "チ", "tsu": "ツ", "tu": "ツ", "te": "テ", "to": "ト",
+	"na": "ナ", "ni": "ニ", "nu": "ヌ", "ne": "ネ", "no": "ノ",
+	"ha": "ハ", "hi": "ヒ", "fu": "フ", "hu": "フ", "he": "ヘ", "ho": "ホ",
+	"ma": "マ", "mi": "ミ", "mu": "ム", "me": "メ", "mo": "モ",
+	"ya": "ヤ", "yu": "ユ", "yo": "ヨ",
+	"ra": "ラ", "ri": "リ", "ru": "ル", "re": "レ", "ro": "ロ",
+	"wa": "ワ", "wo": "ヲ",
+	"ga": "ガ", "gi": "ギ", "gu": "グ", "ge": "ゲ", "go": "ゴ",
+	"za": "ザ", "ji": "ジ", "zi": "ジ", "zu": "ズ", "ze": "ゼ", "zo": "ゾ",
+	"da": "ダ", "di": "ヂ", "du": "ヅ", "de": "デ", "do": "ド",
+	"ba": "バ", "bi": "ビ", "bu": "ブ", "be": "ベ", "bo": "ボ",
+	"pa": "パ", "pi": "ピ", "pu": "プ", "pe": "ペ", "po": "ポ",
+	"vu": "ヴ",
+}
+
+func init() {
+	// in code order from 0xa1
+	codes := "。「」、・ヲァィゥェォャュョッー" + "アイウエオカキクケコサシスセソタチツテト" + "ナニヌネノハヒフヘホマミムメモヤユヨラリルレロワン"
+	for i, r := range []rune(codes) {
+		katakanaCodes[r] = []byte{0xa1 + byte(i)}
+	}
+	voiced := []rune("ガギグゲゴザジズゼゾダヂヅデドバビブベボヴ")
+	plain := []rune("カキクケコサシスセソタチツテトハヒフヘホウ")
+	for i, r := range voiced {
+		katakanaCodes[r] = append(katakanaCodes[plain[i]][:1:1], 0xde)
+	}
+	semiVoiced, plain := []rune("パピプペポ"), plain[15:20]
+	for i, r := range semiVoiced {
+		katakanaCodes[r] = append(katakanaCodes[plain[i]][:1:1], 0xdf)
+	}
+	katakanaCodes['゛'], katakanaCodes['゜'], katakanaCodes['　'] = []byte{0xde}, []byte{0xdf}, []byte{' '}
+
+	// contracted syllables, eg kya is キ followed by a small ャ
+	for _, c := range []struct{ romaji, i string }{
+		{"ky", "キ"}, {"gy", "ギ"}, {"sh", "シ"}, {"j", "ジ"}, {"ch", "チ"}, {"ny", "ニ"}, {"hy", "ヒ"}, {"by", "ビ"},
+		{"py", "ピ"}, {"my", "ミ"}, {"ry", "リ"},
+	} {
+		romajiKatakana[c.romaji+"a"] = c.i + "ャ"
+		romajiKatakana[c.romaji+"u"] = c.i + "ュ"
+		romajiKatakana[c.romaji+"o"] = c.i + "ョ"
+	}
+}
+
+// Katakana returns the character codes of s for the A00 character ROM, which has half-width katakana at 0xa1 -
+// 0xdf. s can have full-width or half-width katakana, Japanese punctuation and printable ASCII (except \ and ~,
+// which are ¥ and → in the ROM). ErrUnsupportedRune is returned for anything else, eg hiragana or kanji.
+func Katakana(s string) ([]byte, error) {
+	var codes []byte
+	for _, r := range s {
+		switch {
+		case r >= 0xff61 && r <= 0xff9f:
+			// half-width katakana are in the same order as the ROM
+			codes = append(codes, byte(r-0xff61+0xa1))
+		case r >= ' ' && r < utf8.RuneSelf-1 && r != '\\' && r != '~':
+			codes = append(codes, byte(r))
+		case katakanaCodes[r] != nil:
+			codes = append(codes, katakanaCodes[r]...)
+		default:
+			return nil, fmt.Errorf("%w: %q", ErrUnsupportedRune, r)
+		}
+	}
+	return codes, nil
+}
+
+// KatakanaFromRomaji returns the character codes of romaji written in katakana, eg "ramen" is ラメン, for the A00
+// character ROM. A doubled consonant is a small ッ (eg "kitte"), n before a consonant or at the end is ン (write
+// "n'" for ン before a vowel) and - is the long vowel mark ー. Case doesn't matter. Other ASCII characters (spaces,
+// digits and punctuation) are written as they are, ErrUnsupportedRune is returned for letters that aren't romaji.
+func KatakanaFromRomaji(romaji string) ([]byte, error) {
+	s := strings.ToLower(romaji)
+	var kana strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '-':
+			kana.WriteString("ー")
+			i++
+			continue
+		case c < 'a' || c > 'z':
+			kana.WriteByte(c)
+			i++
+			continue
+		case c == 'n' && (i+1 == len(s) || !isRomajiVowel(s[i+1], true)):
+			kana.WriteString("ン")
+			i++
+			if i < len(s) && s[i] == '\'' {
+				i++
+			}
+			continue
+		case c != 'n' && !isRomajiVowel(c, false) && i+1 < len(s) && (s[i+1] == c || c == 't' && s[i+1] == 'c'):
+			// a doubled consonant, or tch as in matcha
+			kana.WriteString("ッ")
+			i++
+			continue
+		}
+
+		n := 0
+		for l := 3; l > 0 && n == 0; l-- {
+			if i+l <= len(s) && romajiKatakana[s[i:i+l]] != "" {
+				n = l
+			}
+		}
+		if n == 0 {
+			// s is lowercased so its offsets may not be the same as romaji's
+			r, _ := utf8.DecodeRuneInString(s[i:])
+			return nil, fmt.Errorf("%w: %q in %q", ErrUnsupportedRune, r, romaji)
+		}
+		kana.WriteString(romajiKatakana[s[i:i+n]])
+		i += n
+	}
+	return Katakana(kana.String())
+}
+
+// isRomajiVowel reports whether c is a vowel, or a y if y is true.
+func isRomajiVowel(c byte, y bool) bool {
+	return c == 'a' || c == 'i' || c == 'u' || c == 'e' || c == 'o' || (y && c == 'y')
+}
+
+// DisplayKatakana displays s written in katakana at the specified position like DisplayString, see Katakana for
+// what s can have in it.
+func (hd *Hd44780I2c) DisplayKatakana(s string, line, pos byte) error {
+	codes, err := Katakana(s)
+	if err != nil {
+		return err
+	}
+	return hd.DisplayBytes(codes, line, pos)
+}
@@ -0,0 +1,50 @@
+package hd44780
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestKatakana(t *testing.T) {
+	tests := []struct {
+		romaji, kana string
+		codes        []byte
+	}{
+		{"ra-men 500", "ラーメン 500", []byte{0xd7, 0xb0, 0xd2, 0xdd, ' ', '5', '0', '0'}},
+		{"kitte", "キッテ", []byte{0xb7, 0xaf, 0xc3}},
+		{"Toukyou", "トウキョウ", []byte{0xc4, 0xb3, 0xb7, 0xae, 0xb3}},
+		{"pan", "ﾊﾟﾝ", []byte{0xca, 0xdf, 0xdd}},
+		{"konnichiwa", "コンニチワ", []byte{0xba, 0xdd, 0xc6, 0xc1, 0xdc}},
+		{"gakkou", "ガッコウ", []byte{0xb6, 0xde, 0xaf, 0xba, 0xb3}},
+	}
+	for _, tt := range tests {
+		got, err := Katakana(tt.kana)
+		if err != nil {
+			t.Errorf("Katakana(%q): %v", tt.kana, err)
+		} else if !reflect.DeepEqual(got, tt.codes) {
+			t.Errorf("Katakana(%q) = % x, want % x", tt.kana, got, tt.codes)
+		}
+		got, err = KatakanaFromRomaji(tt.romaji)
+		if err != nil {
+			t.Errorf("KatakanaFromRomaji(%q): %v", tt.romaji, err)
+		} else if !reflect.DeepEqual(got, tt.codes) {
+			t.Errorf("KatakanaFromRomaji(%q) = % x, want % x", tt.romaji, got, tt.codes)
+		}
+	}
+
+	_, err := Katakana("ひらがな")
+	if !errors.Is(err, ErrUnsupportedRune) {
+		t.Errorf("got %v for hiragana, want ErrUnsupportedRune", err)
+	}
+	_, err = KatakanaFromRomaji("xyz")
+	if !errors.Is(err, ErrUnsupportedRune) {
+		t.Errorf("got %v for xyz, want ErrUnsupportedRune", err)
+	}
+	// Ⱥ is longer once it's lowercased, the letter reported is still the right one
+	_, err = KatakanaFromRomaji("Ⱥx")
+	if !errors.Is(err, ErrUnsupportedRune) || !strings.Contains(err.Error(), "'x'") {
+		t.Errorf("got %v for Ⱥx, want ErrUnsupportedRune for 'x'", err)
+	}
+}
@@ -0,0 +1,97 @@
+package hd44780
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Panel is a group of named fields, eg the readings on a dashboard, that are set one by one and then written to
+// the display together by Render.
+type Panel struct {
+	hd *Hd44780I2c
+	// fields are in display order, top to bottom and left to right
+	fields []*panelField
+	byName map[string]*panelField
+}
+
+// panelField is a Field of a Panel and its name, value is what it's to be set to on the next Render if pending is
+// set.
+type panelField struct {
+	*Field
+	name, value string
+	pending     bool
+}
+
+// NewPanel returns a Panel without any fields.
+func (hd *Hd44780I2c) NewPanel() *Panel {
+	return &Panel{hd: hd, byName: make(map[string]*panelField)}
+}
+
+// AddField adds a field called name that's width characters wide starting at col on line, it's blank until it's
+// set. ErrOverlap is returned if it overlaps a field that's already in the panel, ErrInvalidLine or ErrInvalidPos
+// if it isn't all on the display. The Field returned is the same one the panel updates, it can also be set directly
+// with Field.Set, Render then keeps what it was set to until the value is set with Panel.Set.
+func (p *Panel) AddField(name string, line, col, width byte) (*Field, error) {
+	if _, ok := p.byName[name]; ok {
+		return nil, fmt.Errorf("hd44780: panel already has a field called %q", name)
+	}
+	if line >= p.hd.rows {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidLine, line)
+	}
+	if width == 0 || int(col)+int(width) > int(p.hd.cols) {
+		return nil, fmt.Errorf("%w: field %q is %d wide at %d", ErrInvalidPos, name, width, col)
+	}
+	for _, f := range p.fields {
+		if f.line == line && col < f.col+f.width && f.col < col+width {
+			return nil, fmt.Errorf("%w: %q and %q", ErrOverlap, f.name, name)
+		}
+	}
+
+	f := &panelField{Field: p.hd.NewField(line, col, width), name: name}
+	p.fields = append(p.fields, f)
+	sort.Slice(p.fields, func(i, j int) bool {
+		a, b := p.fields[i], p.fields[j]
+		return a.line < b.line || (a.line == b.line && a.col < b.col)
+	})
+	p.byName[name] = f
+	return f.Field, nil
+}
+
+// Set sets the value of the named field, it's shown on the next Render.
+func (p *Panel) Set(name, value string) error {
+	f, ok := p.byName[name]
+	if !ok {
+		return fmt.Errorf("hd44780: panel has no field called %q", name)
+	}
+	f.value, f.pending = value, true
+	return nil
+}
+
+// Render writes the fields that have changed since the last Render to the display in a Transaction. They're
+// written top to bottom and left to right, a field that starts where the last one written ended doesn't need the
+// address to be sent.
+func (p *Panel) Render() error {
+	return p.hd.Transaction(func(tx *Tx) error {
+		for _, f := range p.fields {
+			// a field that was set directly with Field.Set keeps what it was set to
+			value := f.last
+			if f.pending {
+				value = f.value
+			}
+			row, col := tx.Cursor()
+			err := f.set(value, !tx.rotated && row == f.line && col == f.col)
+			if err != nil {
+				return err
+			}
+			f.pending = false
+		}
+		return nil
+	})
+}
+
+// Invalidate makes the next Render write every field, use it after the display has been cleared or overwritten.
+func (p *Panel) Invalidate() {
+	for _, f := range p.fields {
+		f.Invalidate()
+	}
+}
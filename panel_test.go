@@ -0,0 +1,104 @@
+package hd44780
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPanelRender(t *testing.T) {
+	hd, bus := newTestDisplay(t)
+	p := hd.NewPanel()
+	for _, f := range []struct {
+		name             string
+		line, col, width byte
+	}{{"humidity", 0, 4, 2}, {"temp", 0, 0, 4}, {"status", 1, 0, 3}} {
+		_, err := p.AddField(f.name, f.line, f.col, f.width)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := p.AddField("overlap", 0, 3, 2)
+	if !errors.Is(err, ErrOverlap) {
+		t.Errorf("got %v adding an overlapping field, want ErrOverlap", err)
+	}
+
+	for name, value := range map[string]string{"temp": "21C", "humidity": "40", "status": "ok"} {
+		err = p.Set(name, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = p.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(hd.ddram[0][0x00:0x06]) + string(hd.ddram[0][0x40:0x43]); got != "21C 40ok " {
+		t.Fatalf("got %q displayed, want %q", got, "21C 40ok ")
+	}
+	bus.written = nil
+
+	// only the changed field is written
+	err = p.Set("humidity", "41")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = p.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []instruction{
+		{registerSelectLow, lcdSetDDRamAddr | 0x04},
+		{registerSelectHigh, '4'},
+		{registerSelectHigh, '1'},
+	}
+	got := bus.instructions(hd.PinMap)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got instructions %#v, want %#v", got, want)
+	}
+}
+
+func TestPanelFieldSetDirectly(t *testing.T) {
+	hd, _ := newTestDisplay(t)
+	p := hd.NewPanel()
+	temp, err := p.AddField("temp", 0, 0, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = p.AddField("status", 1, 0, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a field set directly keeps its value when the panel renders, even after being invalidated
+	err = temp.Set("21C")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = p.Set("status", "ok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		err = p.Render()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(hd.ddram[0][0x00:0x04]); got != "21C " {
+			t.Fatalf("render %d: got %q in the field set directly, want %q", i, got, "21C ")
+		}
+		p.Invalidate()
+	}
+
+	err = p.Set("temp", "22C")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = p.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(hd.ddram[0][0x00:0x04]); got != "22C " {
+		t.Errorf("got %q after Panel.Set, want %q", got, "22C ")
+	}
+}
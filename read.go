@@ -0,0 +1,318 @@
+package hd44780
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// reader returns the bus as an io.Reader, ErrReadNotSupported is returned if it doesn't implement io.Reader or if RW
+// is the second controller's EN with DualController, as raising RW to read would clock garbage into that controller.
+func (hd *Hd44780I2c) reader() (io.Reader, error) {
+	if hd.dual && hd.en2 == hd.PinMap.RW {
+		return nil, fmt.Errorf("%w: RW is the second controller's EN", ErrReadNotSupported)
+	}
+	r, ok := hd.bus.(io.Reader)
+	if !ok {
+		return nil, ErrReadNotSupported
+	}
+	return r, nil
+}
+
+// readByte reads a byte from the controller, with rs low it's the busy flag and address counter and with rs high
+// it's the data at the address counter (which then moves on as it does for a write). RW must be wired to the port
+// expander and the bus must implement io.Reader.
+//
+// The data pins are set high while reading, a PCF8574 (and similar) can only use a pin as an input when it's high.
+func (hd *Hd44780I2c) readByte(rs RegisterSelect) (byte, error) {
+	r, err := hd.reader()
+	if err != nil {
+		return 0x0, err
+	}
+
+	dataPins := []byte{hd.PinMap.D4, hd.PinMap.D5, hd.PinMap.D6, hd.PinMap.D7}
+	if hd.EightBitModeEnabled() {
+		dataPins = []byte{
+			hd.PinMap.D0, hd.PinMap.D1, hd.PinMap.D2, hd.PinMap.D3,
+			hd.PinMap.D4, hd.PinMap.D5, hd.PinMap.D6, hd.PinMap.D7,
+		}
+	}
+
+	var idle uint16 = 0x00
+	idle |= hd.backlightBit()
+	ins := idle | uint16(rs)<<hd.PinMap.RS | 0x01<<hd.PinMap.RW
+	for _, pin := range dataPins {
+		ins |= 0x01 << pin
+	}
+
+	// 1 transfer in 8-bit mode, 2 nibbles (high first) in 4-bit mode
+	transfers := 2
+	if hd.EightBitModeEnabled() {
+		transfers = 1
+	}
+
+	var data byte
+	for i := 0; i < transfers; i++ {
+		err = hd.writePins(ins)
+		if err != nil {
+			return 0x0, err
+		}
+		time.Sleep(hd.pulseWait(0))
+		err = hd.writePins(ins | hd.controllerEnableBit())
+		if err != nil {
+			return 0x0, err
+		}
+		// the data is valid a while after EN goes high, well within the minimum time it's high
+		time.Sleep(hd.pulseWait(1))
+
+		pins, err := hd.readPins(r)
+		if err != nil {
+			return 0x0, err
+		}
+		err = hd.writePins(ins)
+		if err != nil {
+			return 0x0, err
+		}
+
+		var part byte
+		for bit, pin := range dataPins {
+			part |= byte((pins>>pin)&0x01) << bit
+		}
+		data = data<<4 | part
+	}
+
+	// back to writing
+	err = hd.writePins(idle)
+	if err != nil {
+		return 0x0, err
+	}
+	return data, nil
+}
+
+// readBusyFlag reads the busy flag and the address counter. On a PCF8574 (or similar quasi-bidirectional) backpack
+// this means setting the data pins high so they can be used as inputs, setting RW high and RS low, then for each
+// nibble (high first) raising EN, reading the port and lowering EN again, see readByte. RW must be wired.
+func (hd *Hd44780I2c) readBusyFlag() (busy bool, addr byte, err error) {
+	ac, err := hd.readByte(registerSelectLow)
+	if err != nil {
+		return false, 0x00, err
+	}
+	return ac&busyBit > 0, ac &^ busyBit, nil
+}
+
+// readPins reads the state of the port expander's pins, 16 pins in 8-bit mode otherwise 8.
+func (hd *Hd44780I2c) readPins(r io.Reader) (uint16, error) {
+	err := hd.checkStuck()
+	if err != nil {
+		return 0x0, err
+	}
+
+	buf := make([]byte, 1)
+	if hd.EightBitModeEnabled() {
+		buf = make([]byte, 2)
+	}
+	_, err = io.ReadFull(r, buf)
+	if err != nil {
+		return 0x0, err
+	}
+
+	pins := uint16(buf[0])
+	if len(buf) == 2 {
+		pins |= uint16(buf[1]) << 8
+	}
+	return pins, nil
+}
+
+// connectionCheckPattern is written to CGRAM and read back to check the display is there, alternate bits are set so
+// stuck high or low data lines are caught.
+var connectionCheckPattern = CustomChar{0x15, 0x0a, 0x15, 0x0a, 0x15, 0x0a, 0x15, 0x0a}
+
+// busModeCheckAddr is the DDRAM address verifyMode sets, it has bits set in both nibbles and is in the first line so
+// it's valid in both 1 and 2-line mode.
+const busModeCheckAddr byte = 0x25
+
+// verifyMode checks that the controller is in the bus mode that's been set by setting the address counter and
+// reading it back. A controller that's still in 8-bit mode when 4-bit mode is wanted takes each nibble as a whole
+// instruction so the address is wrong. The controller may still be busy with the instruction, the busy flag is polled
+// until it's done.
+func (hd *Hd44780I2c) verifyMode() error {
+	err := hd.WriteInstruction(lcdSetDDRamAddr | busModeCheckAddr)
+	if err != nil {
+		return err
+	}
+	busy, addr, err := hd.pollBusyFlag(hd.timing.Clear)
+	if err != nil {
+		return err
+	}
+	if busy || addr != busModeCheckAddr {
+		bits := 4
+		if hd.EightBitModeEnabled() {
+			bits = 8
+		}
+		return fmt.Errorf("%w: not in %d-bit mode, set address %#02x and read %#02x (busy %v)", ErrBusMode, bits,
+			busModeCheckAddr, addr, busy)
+	}
+	// back to the first cell, where the cursor is tracked as being
+	return hd.WriteInstruction(lcdSetDDRamAddr)
+}
+
+// checkConnection writes a pattern to CGRAM slot 0 and reads it back.
+func (hd *Hd44780I2c) checkConnection() error {
+	err := hd.SetCustomChar(0, connectionCheckPattern)
+	if err != nil {
+		return err
+	}
+	err = hd.WriteInstruction(cgramAddr(0))
+	if err != nil {
+		return err
+	}
+	for i, want := range connectionCheckPattern {
+		got, err := hd.readByte(registerSelectHigh)
+		if err != nil {
+			return err
+		}
+		// only the 5 bits of each line that are used are compared
+		if got&0x1f != want {
+			// the mismatch is more useful than an error from restoring
+			_ = hd.restoreDDRamAddr()
+			return fmt.Errorf("CGRAM line %d: wrote %#02x and read %#02x", i, want, got)
+		}
+	}
+	return hd.restoreDDRamAddr()
+}
+
+// waitReady waits for the controller to finish an instruction that takes up to max. With PollBusyFlag set the busy
+// flag is read until it's clear (or max has passed), otherwise it sleeps for max.
+func (hd *Hd44780I2c) waitReady(max time.Duration) error {
+	if _, err := hd.reader(); err != nil || !hd.pollBusy {
+		time.Sleep(max)
+		return nil
+	}
+
+	_, _, err := hd.pollBusyFlag(max)
+	return err
+}
+
+// pollBusyFlag reads the busy flag until it's clear or max has passed, it returns the last busy flag and address
+// counter read. A busy flag that's set is always read again at least once, as reading it over a slow bus can take
+// longer than max.
+func (hd *Hd44780I2c) pollBusyFlag(max time.Duration) (busy bool, addr byte, err error) {
+	deadline := time.Now().Add(max)
+	for reads := 1; ; reads++ {
+		busy, addr, err = hd.readBusyFlag()
+		if err != nil || !busy || (reads > 1 && !time.Now().Before(deadline)) {
+			return busy, addr, err
+		}
+	}
+}
+
+// ReadDDRAM reads n bytes of DDRAM starting at addr, eg to move part of the display somewhere the hardware shift
+// can't. The address counter moves in the direction of the entry mode as it's read, so in entry decrement mode the
+// bytes are from addr downwards. Afterwards the address is set back to the tracked cursor position so writes carry
+// on from where they were. RW must be wired to the port expander and the bus must implement io.Reader, otherwise
+// ErrReadNotSupported is returned before anything is sent.
+//
+// The busy flag isn't polled, instead each read waits for the longest time the controller takes to move the address
+// counter on after a read, which is the same as the delay after a write.
+func (hd *Hd44780I2c) ReadDDRAM(addr byte, n int) ([]byte, error) {
+	if _, err := hd.reader(); err != nil {
+		return nil, err
+	}
+	last := int(addr) + n - 1
+	if !hd.EntryIncrementEnabled() {
+		last = int(addr) - n + 1
+	}
+	if n < 1 || int(addr) >= len(hd.ddram[0]) || last < 0 || last >= len(hd.ddram[0]) {
+		return nil, fmt.Errorf("%w: %d bytes from %#02x", ErrInvalidPos, n, addr)
+	}
+
+	cursor := hd.cursorAddress()
+	err := hd.WriteInstruction(lcdSetDDRamAddr | addr)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, n)
+	for i := range data {
+		data[i], err = hd.readByte(registerSelectHigh)
+		if err != nil {
+			return nil, err
+		}
+		time.Sleep(hd.timing.Write)
+	}
+
+	// what's been read is what's really there
+	a := int(addr)
+	for _, b := range data {
+		hd.ddram[hd.ctrl][a] = b
+		if hd.EntryIncrementEnabled() {
+			a++
+		} else {
+			a--
+		}
+	}
+
+	return data, hd.WriteInstruction(lcdSetDDRamAddr | cursor)
+}
+
+// trackRAM keeps track of whether the address counter is in CGRAM or DDRAM from the instructions that set it.
+func (hd *Hd44780I2c) trackRAM(instruction byte) {
+	switch {
+	case instruction&lcdSetDDRamAddr > 0:
+		hd.inCGRAM = false
+	case instruction&lcdSetCGRamAddr > 0:
+		hd.inCGRAM = true
+	case instruction == lcdClearDisplay, instruction&^0x01 == lcdReturnHome:
+		hd.inCGRAM = false
+	}
+}
+
+// trackModes records the mode instructions sent so SetMode can skip the registers that haven't changed. Clear
+// changes the entry mode so it's no longer known.
+func (hd *Hd44780I2c) trackModes(instruction byte) {
+	switch {
+	case instruction&lcdSetDDRamAddr > 0, instruction&lcdSetCGRamAddr > 0:
+	case instruction&byte(lcdSetFunctionMode) > 0:
+		hd.sentFunction = instruction
+	case instruction&lcdCursorShift > 0:
+	case instruction&byte(lcdSetDisplayMode) > 0:
+		hd.sentDisplay = instruction
+	case instruction&byte(lcdSetEntryMode) > 0:
+		hd.sentEntry = instruction
+	case instruction == lcdClearDisplay:
+		hd.sentEntry = 0
+	}
+}
+
+// verify reads back the data that was just written and returns ErrVerifyFailed if it's different.
+func (hd *Hd44780I2c) verify(data byte) error {
+	ac, err := hd.readByte(registerSelectLow)
+	if err != nil {
+		return err
+	}
+
+	// the address counter has already moved on from the address that was written to
+	address := ac &^ busyBit
+	if hd.EntryIncrementEnabled() {
+		address--
+	} else {
+		address++
+	}
+	setAddr := lcdSetDDRamAddr | address&^busyBit
+	if hd.inCGRAM {
+		setAddr = lcdSetCGRamAddr | address&0x3f
+	}
+
+	err = hd.write(setAddr, registerSelectLow)
+	if err != nil {
+		return err
+	}
+	got, err := hd.readByte(registerSelectHigh)
+	if err != nil {
+		return err
+	}
+	if got != data {
+		return fmt.Errorf("%w: wrote %#02x and read %#02x", ErrVerifyFailed, data, got)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package hd44780
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// readBus is a fakeBus where each read returns the next of the port values in reads.
+type readBus struct {
+	fakeBus
+	reads []byte
+}
+
+func (b *readBus) Read(buf []byte) (int, error) {
+	n := copy(buf, b.reads)
+	b.reads = b.reads[n:]
+	return n, nil
+}
+
+func TestReadBusyFlag(t *testing.T) {
+	// busy with the address counter at 0x25, read as the nibbles 0xa and 0x5 on D4 - D7
+	bus := &readBus{reads: []byte{0xa0, 0x50}}
+	hd, err := NewHd44780(bus, PCF8574PinMap, RowAddress16Col, SkipInit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus.written = nil
+
+	busy, addr, err := hd.readBusyFlag()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !busy || addr != 0x25 {
+		t.Errorf("got busy %v and address %#02x, want true and 0x25", busy, addr)
+	}
+
+	// data pins and RW high, RS low and the backlight on for each nibble, then back to writing
+	want := []byte{0xfa, 0xfe, 0xfa, 0xfa, 0xfe, 0xfa, 0x08}
+	if !reflect.DeepEqual(bus.written, want) {
+		t.Errorf("got %#v written, want %#v", bus.written, want)
+	}
+}
+
+func TestVerifyBusMode(t *testing.T) {
+	// the address counter read back as the nibbles 0x2 and 0x5 on D4 - D7, busy the first time
+	bus := &readBus{reads: []byte{0xa0, 0x50, 0x20, 0x50}}
+	_, err := NewHd44780(bus, PCF8574PinMap, RowAddress16Col, VerifyBusMode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bus.reads) > 0 {
+		t.Errorf("the busy flag wasn't read again, %d reads left", len(bus.reads))
+	}
+
+	// a controller in 8-bit mode takes 0x02 then 0x05 as 2 instructions, so the address isn't set
+	bus = &readBus{reads: []byte{0x00, 0x00}}
+	_, err = NewHd44780(bus, PCF8574PinMap, RowAddress16Col, VerifyBusMode)
+	if !errors.Is(err, ErrBusMode) || !errors.Is(err, ErrInitFailed) {
+		t.Errorf("got %v, want ErrBusMode as an ErrInitFailed InitError", err)
+	}
+}
+
+func TestInvertCustomChar(t *testing.T) {
+	// each line reads back as 0x0a, the nibbles 0x0 and 0xa on D4 - D7
+	var reads []byte
+	for i := 0; i < 8; i++ {
+		reads = append(reads, 0x00, 0xa0)
+	}
+	bus := &readBus{reads: reads}
+	hd, err := NewHd44780(bus, PCF8574PinMap, RowAddress16Col, SkipInit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []byte
+	hd.OnWrite = func(data byte, rs RegisterSelect) {
+		if rs == DataRegister {
+			lines = append(lines, data)
+		}
+	}
+
+	err = hd.InvertCustomChar(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := bytes.Repeat([]byte{0x15}, 8); !bytes.Equal(lines, want) {
+		t.Errorf("got lines %#v written, want %#v", lines, want)
+	}
+
+	// a bus that can't be read fails reading rather than writing
+	hd, _ = newTestDisplay(t)
+	err = hd.InvertCustomChar(0)
+	var ccErr *CustomCharError
+	if !errors.Is(err, ErrReadNotSupported) || errors.As(err, &ccErr) {
+		t.Errorf("got %v, want a read error wrapping ErrReadNotSupported", err)
+	}
+
+	err = hd.SetMode(Dots5x10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = hd.InvertCustomChar(0)
+	if !errors.Is(err, ErrUnsupported) {
+		t.Errorf("got %v in 5x10-pixel mode, want ErrUnsupported", err)
+	}
+}
+
+func TestCheckConnectionWithoutReads(t *testing.T) {
+	bus := &fakeBus{}
+	_, err := NewHd44780(bus, PCF8574PinMap, RowAddress16Col, CheckConnection)
+	var initErr *InitError
+	if err != ErrReadNotSupported || errors.As(err, &initErr) {
+		t.Errorf("got %v, want ErrReadNotSupported", err)
+	}
+	if len(bus.written) > 0 {
+		t.Errorf("got %d writes, want nothing sent", len(bus.written))
+	}
+}
+
+func TestPollBusyFlag(t *testing.T) {
+	// long enough that the test times out if it's waited for rather than the busy flag
+	timing := DefaultTiming
+	timing.Clear, timing.Home = time.Hour, time.Hour
+
+	// busy once then clear, for each of Clear and Home
+	bus := &readBus{reads: []byte{0x80, 0x00, 0x00, 0x00, 0x80, 0x00, 0x00, 0x00}}
+	hd, err := NewHd44780(bus, PCF8574PinMap, RowAddress16Col, SkipInit, SetTiming(timing), PollBusyFlag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = hd.Clear()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bus.reads) != 4 {
+		t.Errorf("got %d reads left after Clear, want 4", len(bus.reads))
+	}
+	err = hd.Home()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bus.reads) > 0 {
+		t.Errorf("got %d reads left after Home, want 0", len(bus.reads))
+	}
+}
+
+func TestDualControllerNoReads(t *testing.T) {
+	// with RW as the second controller's EN reading would send that controller garbage
+	for _, m := range []ModeSetter{PollBusyFlag, VerifyBusMode, CheckConnection} {
+		_, err := NewHd44780(&readBus{}, PCF8574PinMap, RowAddress40x4, DualController(PCF8574PinMap.RW), m)
+		if !errors.Is(err, ErrReadNotSupported) {
+			t.Errorf("got %v, want ErrReadNotSupported", err)
+		}
+	}
+
+	bus := &readBus{reads: []byte{0x00, 0x00}}
+	hd, err := NewHd44780(bus, PCF8574PinMap, RowAddress40x4, SkipInit, DualController(PCF8574PinMap.RW))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus.written = nil
+	_, err = hd.ReadDDRAM(0x00, 1)
+	if !errors.Is(err, ErrReadNotSupported) {
+		t.Errorf("got %v from ReadDDRAM, want ErrReadNotSupported", err)
+	}
+	if len(bus.written) > 0 {
+		t.Errorf("got %d writes from ReadDDRAM, want nothing sent", len(bus.written))
+	}
+
+	// PollBusyFlag set afterwards falls back to sleeping
+	err = hd.SetMode(PollBusyFlag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = hd.Clear()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bus.reads) != 2 {
+		t.Errorf("got %d reads left after Clear, want the busy flag not read", len(bus.reads))
+	}
+}
@@ -0,0 +1,47 @@
+package hd44780
+
+import "fmt"
+
+// flipped is the character each character looks like when it's turned upside down, for the ones that look like
+// another character (or themselves) in the A00 character ROM.
+var flipped = map[rune]rune{
+	' ': ' ', '-': '-', '+': '+', '=': '=', '*': '*', ':': ':', '/': '/', '|': '|', '_': '-',
+	'(': ')', ')': '(', '[': ']', ']': '[', '{': '}', '}': '{', '<': '>', '>': '<', '.': '\'', '\'': '.',
+	'0': '0', '1': 'l', '6': '9', '8': '8', '9': '6',
+	'H': 'H', 'I': 'I', 'M': 'W', 'N': 'N', 'O': 'O', 'S': 'S', 'W': 'M', 'X': 'X', 'Z': 'Z',
+	'b': 'q', 'd': 'p', 'l': '1', 'n': 'u', 'o': 'o', 'p': 'd', 'q': 'b', 's': 's', 'u': 'n', 'x': 'x', 'z': 'z',
+}
+
+// Rotate180 is a ModeSetter for displays mounted upside down, DisplayString shows text the right way up by writing
+// it backwards from the opposite corner with each character replaced by the one that looks like it upside down, eg
+// "bob" is written as "qoq". The controller can't rotate characters so only the ones in the table of flipped
+// characters come out the right way up (digits 0, 6, 8 and 9, a few letters and most symbols), the rest are shown
+// upside down. Load upside down custom characters for anything else that's needed.
+//
+// Only DisplayString is rotated, the line and position are still counted from the top left as the reader sees it.
+// Methods that write at the cursor (WriteChar, WriteString and Write) and the other Display methods aren't changed.
+func Rotate180(hd *Hd44780I2c) { hd.rotated = true }
+
+// displayRotated is DisplayString with Rotate180 set. The text is cut off at the edge of the display (the left
+// edge of the controller's line) rather than going into DDRAM that isn't shown.
+func (hd *Hd44780I2c) displayRotated(str string, line, pos byte) error {
+	if line >= hd.rows {
+		return fmt.Errorf("%w: %d", ErrInvalidLine, line)
+	}
+	if pos >= hd.cols {
+		return fmt.Errorf("%w: %d", ErrInvalidPos, pos)
+	}
+
+	text := []rune(str)
+	if room := int(hd.cols - pos); len(text) > room {
+		text = text[:room]
+	}
+	codes := make([]byte, len(text))
+	for i, r := range text {
+		if f, ok := flipped[r]; ok {
+			r = f
+		}
+		codes[len(text)-1-i] = hd.charCode(r)
+	}
+	return hd.DisplayBytes(codes, hd.rows-1-line, hd.cols-pos-byte(len(text)))
+}
@@ -0,0 +1,86 @@
+package hd44780
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// selfTestPause is how long each step of SelfTest is shown for.
+const selfTestPause = 500 * time.Millisecond
+
+// selfTestGlyphs are the special characters SelfTest shows.
+var selfTestGlyphs = []byte{
+	YenSign, RightArrow, LeftArrow, MiddleDot, DegreeSign, Alpha, Beta, Epsilon, Micro, Sigma, Rho, SquareRoot,
+	CentSign, Theta, Infinity, Omega, Summation, Pi, Divide, FullBlock,
+}
+
+// SelfTest runs through the features of the display so a newly wired display can be checked by eye: every cell
+// filled, the special characters, the backlight off and on and each cursor mode. If the bus implements io.Reader a
+// custom character is also written and read back, which needs RW wired, custom character 0 is overwritten. The
+// display is cleared and the cursor modes are put back at the end.
+//
+// Only bus errors (and a read back that doesn't match) can be detected, the error returned says which step failed.
+func (hd *Hd44780I2c) SelfTest() error {
+	cursor := hd.dMode & (lcdUnderlineCursorOn | lcdBlinkCursorOn)
+	full := bytes.Repeat([]byte{FullBlock}, int(hd.cols))
+	glyphs := selfTestGlyphs
+	if len(glyphs) > int(hd.cols) {
+		glyphs = glyphs[:hd.cols]
+	}
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		{"filling the display", func() error {
+			for r := byte(0); r < hd.rows; r++ {
+				err := hd.DisplayBytes(full, r, 0)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+		{"showing special characters", func() error {
+			err := hd.Clear()
+			if err != nil {
+				return err
+			}
+			return hd.DisplayBytes(glyphs, 0, 0)
+		}},
+		{"turning the backlight off", hd.BacklightOff},
+		{"turning the backlight on", hd.BacklightOn},
+		{"turning the underline cursor on", func() error {
+			err := hd.DisplayString("cursor", 0, 0)
+			if err != nil {
+				return err
+			}
+			return hd.SetMode(UnderlineCursorOn, BlinkCursorOff)
+		}},
+		{"turning the blinking cursor on", func() error { return hd.SetMode(UnderlineCursorOff, BlinkCursorOn) }},
+		{"turning both cursors on", func() error { return hd.SetMode(UnderlineCursorOn, BlinkCursorOn) }},
+		{"reading a custom character back", func() error {
+			if _, err := hd.reader(); err != nil {
+				return nil
+			}
+			return hd.checkConnection()
+		}},
+		{"clearing the display", func() error {
+			hd.dMode = hd.dMode&^(lcdUnderlineCursorOn|lcdBlinkCursorOn) | cursor
+			err := hd.ApplyDisplayMode()
+			if err != nil {
+				return err
+			}
+			return hd.Clear()
+		}},
+	}
+
+	for _, s := range steps {
+		err := s.run()
+		if err != nil {
+			return fmt.Errorf("hd44780: self test failed %s: %w", s.name, err)
+		}
+		time.Sleep(selfTestPause)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package hd44780
+
+// MCP23S17 registers, with IOCON.BANK = 0 (the default) so the A and B registers of each type are next to each other
+const (
+	mcp23s17IODIRA byte = 0x00
+	mcp23s17GPIOA  byte = 0x12
+
+	mcp23s17Opcode byte = 0x40 // write, the read opcode has bit 0 set
+)
+
+// MCP23S17 adapts an MCP23S17 SPI port expander to a BusWriter. Pins 0 - 7 are port A and pins 8 - 15 port B, each
+// write sets port A then (if there's a second byte) port B. Reads aren't supported as the pins would have to be
+// switched to inputs, so RW should be tied low.
+type MCP23S17 struct {
+	// Conn is the SPI connection, eg a periph.io/x/conn/v3/spi.Conn.
+	Conn PeriphConn
+	// Addr is the hardware address (A2 - A0), it's only used by the expander if IOCON.HAEN is set.
+	Addr byte
+}
+
+// Init makes all of the expander's pins outputs, it must be called before the expander is used.
+func (m *MCP23S17) Init() error {
+	return m.Conn.Tx([]byte{m.opcode(), mcp23s17IODIRA, 0x00, 0x00}, nil)
+}
+
+// Write implements BusWriter.
+func (m *MCP23S17) Write(buf []byte) (int, error) {
+	err := m.Conn.Tx(append([]byte{m.opcode(), mcp23s17GPIOA}, buf...), nil)
+	if err != nil {
+		return 0, err
+	}
+	return len(buf), nil
+}
+
+func (m *MCP23S17) opcode() byte {
+	return mcp23s17Opcode | (m.Addr&0x07)<<1
+}
+
+// NewHd44780SPI returns a new Connection based on an MCP23S17 SPI port expander at hardware address 0, the pin map
+// gives the expander's pin numbers in the same way as for an I²C expander. For another address use an MCP23S17 with
+// NewHd44780.
+func NewHd44780SPI(conn PeriphConn, pinMap I2CPinMap, rowAddr RowAddress, modes ...ModeSetter) (*Hd44780I2c, error) {
+	expander := &MCP23S17{Conn: conn}
+	err := expander.Init()
+	if err != nil {
+		return nil, &InitError{Stage: ErrBusUnreachable, Err: err}
+	}
+	return NewHd44780(expander, pinMap, rowAddr, modes...)
+}
@@ -0,0 +1,58 @@
+package hd44780
+
+import (
+	"reflect"
+	"testing"
+)
+
+// txConn records the bytes written in each transaction.
+type txConn struct {
+	txs [][]byte
+}
+
+func (c *txConn) Tx(w, r []byte) error {
+	c.txs = append(c.txs, append([]byte(nil), w...))
+	return nil
+}
+
+func TestMCP23S17(t *testing.T) {
+	conn := &txConn{}
+	m := &MCP23S17{Conn: conn, Addr: 0x05}
+
+	err := m.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := m.Write([]byte{0x12, 0x34})
+	if err != nil || n != 2 {
+		t.Fatalf("got %d, %v from Write, want 2, nil", n, err)
+	}
+
+	// the address is in bits 3 - 1 of the opcode, IODIRA and IODIRB are cleared together and GPIOA is followed by
+	// GPIOB as the register address moves on
+	want := [][]byte{
+		{0x4a, mcp23s17IODIRA, 0x00, 0x00},
+		{0x4a, mcp23s17GPIOA, 0x12, 0x34},
+	}
+	if !reflect.DeepEqual(conn.txs, want) {
+		t.Errorf("got transactions %#v, want %#v", conn.txs, want)
+	}
+}
+
+func TestNewHd44780SPI(t *testing.T) {
+	conn := &txConn{}
+	_, err := NewHd44780SPI(conn, PCF8574PinMap, RowAddress16Col, SkipInit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(conn.txs) < 2 || !reflect.DeepEqual(conn.txs[0], []byte{0x40, mcp23s17IODIRA, 0x00, 0x00}) {
+		t.Fatalf("got transactions %#v, want the pins made outputs first", conn.txs)
+	}
+	// in 4-bit mode each write sets port A only
+	for _, tx := range conn.txs[1:] {
+		if len(tx) != 3 || tx[0] != 0x40 || tx[1] != mcp23s17GPIOA {
+			t.Fatalf("got transaction %#v, want a write of GPIOA", tx)
+		}
+	}
+}
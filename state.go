@@ -0,0 +1,108 @@
+package hd44780
+
+// DisplayState is a snapshot of what's on the display and how it's set up, see SaveState.
+type DisplayState struct {
+	// DDRAM is a copy of the display data RAM of each controller, indexed by controller then address. The second
+	// controller's is only used with DualController.
+	DDRAM [2][0x80]byte
+	// Row and Col are the cursor position.
+	Row, Col  byte
+	Backlight bool
+
+	eMode entryMode
+	dMode displayMode
+	fMode functionMode
+}
+
+// SaveState returns the current state of the display, including everything that's been written to it. The display
+// can't be read so the state is what's been sent using the methods of Hd44780I2c (see Cursor).
+func (hd *Hd44780I2c) SaveState() DisplayState {
+	return DisplayState{
+		DDRAM:     hd.ddram,
+		Row:       hd.curRow,
+		Col:       hd.curCol,
+		Backlight: hd.backlight,
+		eMode:     hd.eMode,
+		dMode:     hd.dMode,
+		fMode:     hd.fMode,
+	}
+}
+
+// RestoreState writes everything in state back to the display, eg after turning it off for a screensaver. The
+// visible part of each row is rewritten, custom characters aren't included so they must be loaded again if they've
+// been changed.
+func (hd *Hd44780I2c) RestoreState(state DisplayState) error {
+	// the modes are set last so the rows can be written left to right
+	hd.eMode, hd.dMode, hd.fMode = lcdEntryIncrement, state.dMode, state.fMode
+	err := hd.SetMode()
+	if err != nil {
+		return err
+	}
+
+	row := make([]byte, hd.cols)
+	for r := 0; r < int(hd.rows) && r < len(hd.RowAddr); r++ {
+		ddram := state.DDRAM[hd.rowController(byte(r))]
+		for c := range row {
+			row[c] = ddram[hd.cellAddress(byte(r), byte(c))&0x7f]
+		}
+		err = hd.DisplayBytes(row, byte(r), 0)
+		if err != nil {
+			return err
+		}
+	}
+
+	hd.eMode = state.eMode
+	err = hd.ApplyEntryMode()
+	if err != nil {
+		return err
+	}
+	err = hd.SetCursor(state.Row, state.Col)
+	if err != nil {
+		return err
+	}
+	hd.ddram = state.DDRAM
+
+	if state.Backlight {
+		return hd.BacklightOn()
+	}
+	return hd.BacklightOff()
+}
+
+// clearDDRAM sets the copy of DDRAM to how it is after the display is cleared.
+func (hd *Hd44780I2c) clearDDRAM() {
+	for c := range hd.ddram {
+		for i := range hd.ddram[c] {
+			hd.ddram[c][i] = ' '
+		}
+	}
+}
+
+// setCell records that code was written to the cell at the cursor and calls OnCell if the cell is on the display
+// and has changed.
+func (hd *Hd44780I2c) setCell(code byte) {
+	addr := hd.cursorAddress() & 0x7f
+	changed := hd.ddram[hd.ctrl][addr] != code
+	hd.ddram[hd.ctrl][addr] = code
+	if changed && hd.OnCell != nil && hd.curRow < hd.rows && hd.curCol < hd.cols {
+		hd.OnCell(hd.curRow, hd.curCol, code)
+	}
+}
+
+// cellCode returns the code in the copy of DDRAM for the cell at col on row, from the controller that drives row.
+func (hd *Hd44780I2c) cellCode(row, col byte) byte {
+	return hd.ddram[hd.rowController(row)][hd.cellAddress(row, col)&0x7f]
+}
+
+// clearCells calls OnCell for each cell on the display that isn't blank before it's cleared.
+func (hd *Hd44780I2c) clearCells() {
+	if hd.OnCell == nil {
+		return
+	}
+	for row := byte(0); row < hd.rows; row++ {
+		for col := byte(0); col < hd.cols; col++ {
+			if hd.cellCode(row, col) != ' ' {
+				hd.OnCell(row, col, ' ')
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package hd44780
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRestoreState(t *testing.T) {
+	hd, bus := newTestDisplay(t)
+	err := hd.DisplayString("saved", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = hd.DisplayString("state", 1, 11)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = hd.SetMode(EntryDecrement)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = hd.SetCursor(1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = hd.BacklightOff()
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := hd.SaveState()
+
+	err = hd.SetMode(EntryIncrement)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = hd.Clear()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = hd.DisplayString("something else", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = hd.BacklightOn()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bus.written = nil
+	err = hd.RestoreState(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"saved           ", "           state"}
+	if got := rows(hd); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if hd.curRow != 1 || hd.curCol != 3 || hd.EntryIncrementEnabled() || hd.backlight {
+		t.Errorf("got the cursor at %d,%d, entry increment %v and backlight %v, want 1,3, false and false",
+			hd.curRow, hd.curCol, hd.EntryIncrementEnabled(), hd.backlight)
+	}
+
+	// the rows are written and the entry mode and cursor are set afterwards
+	ins := bus.instructions(hd.PinMap)
+	var data []byte
+	for _, i := range ins {
+		if i.rs == registerSelectHigh {
+			data = append(data, i.data)
+		}
+	}
+	if got := string(data); got != want[0]+want[1] {
+		t.Errorf("got %q written, want the rows %q", got, want[0]+want[1])
+	}
+	tail := []instruction{
+		{registerSelectLow, byte(lcdSetEntryMode)},
+		{registerSelectLow, lcdSetDDRamAddr | 0x43},
+	}
+	if len(ins) < len(tail) || !reflect.DeepEqual(ins[len(ins)-len(tail):], tail) {
+		t.Errorf("got instructions %#v, want them to end with %#v", ins, tail)
+	}
+}
+
+func TestRestoreStateDualController(t *testing.T) {
+	// RW is used as the second controller's EN
+	bus := &fakeBus{}
+	hd, err := NewHd44780(bus, PCF8574PinMap, RowAddress40x4, SkipInit, Dimensions(4, 40),
+		DualController(PCF8574PinMap.RW))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = hd.DisplayString("TOP", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = hd.DisplayString("bot", 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := hd.SaveState()
+
+	err = hd.Clear()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus.written = nil
+	err = hd.RestoreState(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blank := strings.Repeat(" ", 37)
+	want := []string{"TOP" + blank, strings.Repeat(" ", 40), "bot" + blank, strings.Repeat(" ", 40)}
+	if got := rows(hd); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// each controller gets its own rows, and the cursor goes back after "bot" on the second
+	second := PCF8574PinMap
+	second.EN = PCF8574PinMap.RW
+	for i, pm := range []I2CPinMap{PCF8574PinMap, second} {
+		ins := bus.instructions(pm)
+		var data []byte
+		for _, in := range ins {
+			if in.rs == registerSelectHigh {
+				data = append(data, in.data)
+			}
+		}
+		if got := string(data); got != want[2*i]+want[2*i+1] {
+			t.Errorf("controller %d: got %q written, want %q", i+1, got, want[2*i]+want[2*i+1])
+		}
+		if i == 1 && ins[len(ins)-1] != (instruction{registerSelectLow, lcdSetDDRamAddr | 0x03}) {
+			t.Errorf("got %#v as the second controller's last instruction, want the cursor set to 0x03",
+				ins[len(ins)-1])
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package hd44780
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DisplayStringf formats according to a format specifier and displays the result at the specified position, see
+// DisplayString.
+func (hd *Hd44780I2c) DisplayStringf(line, pos byte, format string, args ...interface{}) error {
+	return hd.DisplayString(fmt.Sprintf(format, args...), line, pos)
+}
+
+// DisplayLines displays text that has '\n' between lines, each line is written on the line below the one before
+// starting at the same pos, eg DisplayLines("line1\nline2", 0, 0) fills the first 2 lines. ErrInvalidLine is
+// returned before anything is written if there are more lines than there are rows below line.
+func (hd *Hd44780I2c) DisplayLines(text string, line, pos byte) error {
+	lines := strings.Split(text, "\n")
+	if int(line)+len(lines) > int(hd.rows) {
+		return fmt.Errorf("%w: %d lines from line %d", ErrInvalidLine, len(lines), line)
+	}
+	for i, l := range lines {
+		err := hd.DisplayString(l, line+byte(i), pos)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DisplayStringN is DisplayString that stops at the edge of the display rather than carrying on into DDRAM that
+// isn't shown, it returns the number of runes written so that if it's less than the length of str the rest can be
+// written somewhere else, eg on the next line. In entry decrement mode the text runs left from pos so it stops at
+// the first column.
+func (hd *Hd44780I2c) DisplayStringN(str string, line, pos byte) (int, error) {
+	if _, _, err := hd.address(line, pos); err != nil {
+		return 0, err
+	}
+
+	room := hd.room(pos)
+	r := []rune(str)
+	if len(r) > room {
+		r = r[:room]
+	}
+	return len(r), hd.DisplayString(string(r), line, pos)
+}
+
+// DisplayStringPadded is DisplayString with str padded with spaces to the end of the line, so whatever was on the
+// rest of the line is cleared in the same write rather than with a separate clear that makes it flicker.
+func (hd *Hd44780I2c) DisplayStringPadded(str string, line, pos byte) error {
+	if n := len([]rune(str)); pos < hd.cols && n < int(hd.cols-pos) {
+		str += strings.Repeat(" ", int(hd.cols-pos)-n)
+	}
+	return hd.DisplayString(str, line, pos)
+}
+
+// DisplayNumber displays value with decimals digits after the decimal point followed by unit, right aligned in a
+// field width characters wide starting at col on line, eg for a sensor reading. If it's too wide for the field the
+// field is filled with '#' instead so a truncated number is never mistaken for a real one.
+func (hd *Hd44780I2c) DisplayNumber(value float64, decimals int, unit string, line, col, width byte) error {
+	text := strconv.FormatFloat(value, 'f', decimals, 64) + unit
+	if len([]rune(text)) > int(width) {
+		text = strings.Repeat("#", int(width))
+	}
+	return hd.DisplayString(alignRight(text, int(width)), line, col)
+}
+
+// DisplayWrapped word wraps text to the width of the display and writes it on successive lines, starting at the
+// beginning of startLine. Words longer than a line are split across lines. Each line is padded with spaces to the
+// width of the display so nothing is left of what was there before. Any text that doesn't fit on the remaining lines
+// is returned. In entry decrement mode each line starts at the last column so the text runs right-to-left.
+func (hd *Hd44780I2c) DisplayWrapped(text string, startLine byte) (string, error) {
+	words := strings.Fields(text)
+	var line string
+	for l := startLine; l < hd.rows && len(words) > 0; l++ {
+		line, words = wrapLine(words, int(hd.cols))
+		err := hd.DisplayString(fit(line, int(hd.cols)), l, hd.lineStart())
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(words, " "), nil
+}
+
+// WriteScreen replaces everything on the display with rows, rows[0] is the top line. Each row is padded with spaces
+// or truncated to the width of the display and rows that aren't given are blank.
+//
+// The rows are written in DDRAM address order and the address is only set when a row doesn't carry on from the end
+// of the previous one, eg on a 20x4 display the 3rd row follows straight on from the 1st.
+func (hd *Hd44780I2c) WriteScreen(rows []string) error {
+	order := make([]byte, 0, len(hd.RowAddr))
+	for r := 0; r < int(hd.rows) && r < len(hd.RowAddr); r++ {
+		order = append(order, byte(r))
+	}
+	sort.Slice(order, func(i, j int) bool { return hd.RowAddr[order[i]] < hd.RowAddr[order[j]] })
+
+	next := -1 // the address the address counter is at
+	for _, r := range order {
+		if int(hd.RowAddr[r]) == next && hd.EntryIncrementEnabled() {
+			hd.curRow, hd.curCol = r, 0
+		} else {
+			err := hd.SetCursor(r, 0)
+			if err != nil {
+				return err
+			}
+		}
+
+		var text string
+		if int(r) < len(rows) {
+			text = rows[r]
+		}
+		for _, c := range fit(text, int(hd.cols)) {
+			err := hd.WriteChar(hd.charCode(c))
+			if err != nil {
+				return err
+			}
+		}
+		next = int(hd.RowAddr[r]) + int(hd.cols)
+	}
+	return nil
+}
+
+// DisplayCentered replaces everything on the display with lines, centered both vertically and horizontally. Lines
+// that are longer than the display is wide are truncated, if there are more lines than rows only the first ones are
+// shown. When the space around a line or the block can't be split evenly the extra space goes after it.
+func (hd *Hd44780I2c) DisplayCentered(lines []string) error {
+	if len(lines) > int(hd.rows) {
+		lines = lines[:hd.rows]
+	}
+	rows := make([]string, (int(hd.rows)-len(lines))/2, hd.rows)
+	for _, l := range lines {
+		rows = append(rows, center(l, int(hd.cols)))
+	}
+	return hd.WriteScreen(rows)
+}
+
+// center pads s with spaces so it's in the middle of width characters, it's truncated if it's too long.
+func center(s string, width int) string {
+	n := len([]rune(s))
+	if n >= width {
+		return fit(s, width)
+	}
+	return fit(strings.Repeat(" ", (width-n)/2)+s, width)
+}
+
+// alignRight pads s with spaces at the start so it's width characters long, it's truncated if it's too long.
+func alignRight(s string, width int) string {
+	n := len([]rune(s))
+	if n >= width {
+		return fit(s, width)
+	}
+	return strings.Repeat(" ", width-n) + s
+}
+
+// lineStart returns the column that a line of text starts at, the last column in entry decrement mode as the cursor
+// moves to the left after each character.
+func (hd *Hd44780I2c) lineStart() byte {
+	if hd.EntryIncrementEnabled() {
+		return 0
+	}
+	return hd.cols - 1
+}
+
+// wrapLine takes as many words as fit in width and joins them into a line, the words that are left over are returned.
+// If the first word doesn't fit it's split, the rest of it is returned as the first of the left over words.
+// Lengths are counted in runes as each rune is a character on the display.
+func wrapLine(words []string, width int) (string, []string) {
+	var line []rune
+	for len(words) > 0 {
+		w := []rune(words[0])
+		switch {
+		case len(line) == 0 && len(w) > width:
+			return string(w[:width]), append([]string{string(w[width:])}, words[1:]...)
+		case len(line) == 0:
+			line = w
+		case len(line)+1+len(w) <= width:
+			line = append(append(line, ' '), w...)
+		default:
+			return string(line), words
+		}
+		words = words[1:]
+	}
+	return string(line), words
+}
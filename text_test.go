@@ -0,0 +1,172 @@
+package hd44780
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWrapLine(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		lines []string
+	}{
+		{"the quick brown fox", 16, []string{"the quick brown", "fox"}},
+		{"the quick brown fox", 19, []string{"the quick brown fox"}},
+		{"a supercalifragilistic word", 8, []string{"a", "supercal", "ifragili", "stic", "word"}},
+		{"  extra   spaces  ", 16, []string{"extra spaces"}},
+		{"très café crème", 10, []string{"très café", "crème"}},
+		{"éééé", 3, []string{"ééé", "é"}},
+	}
+
+	for _, tt := range tests {
+		var lines []string
+		words := strings.Fields(tt.text)
+		for len(words) > 0 {
+			var line string
+			line, words = wrapLine(words, tt.width)
+			lines = append(lines, line)
+		}
+		if !reflect.DeepEqual(lines, tt.lines) {
+			t.Errorf("wrap %q at %d: got %q, want %q", tt.text, tt.width, lines, tt.lines)
+		}
+	}
+}
+
+var screen = []string{
+	"the first line of20",
+	"the second line of20",
+	"the third line of 20",
+	"the fourth line of20",
+}
+
+func TestCenter(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  string
+	}{
+		{"hello", 9, "  hello  "},
+		{"hello", 8, " hello  "},
+		{"hello", 5, "hello"},
+		{"hello world", 5, "hello"},
+		{"", 2, "  "},
+	}
+
+	for _, tt := range tests {
+		got := center(tt.text, tt.width)
+		if got != tt.want {
+			t.Errorf("center %q in %d: got %q, want %q", tt.text, tt.width, got, tt.want)
+		}
+	}
+}
+
+func BenchmarkWriteScreen(b *testing.B) {
+	hd, err := NewHd44780(&fakeBus{}, PCF8574PinMap, RowAddress20Col, SkipInit, Dimensions(4, 20))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err = hd.WriteScreen(screen)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDisplayStringScreen(b *testing.B) {
+	hd, err := NewHd44780(&fakeBus{}, PCF8574PinMap, RowAddress20Col, SkipInit, Dimensions(4, 20))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for line, s := range screen {
+			err = hd.DisplayString(s, byte(line), 0)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestDisplayStringPadded(t *testing.T) {
+	hd, _ := newTestDisplay(t)
+	err := hd.DisplayString("a long message", 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = hd.DisplayStringPadded("short", 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(hd.ddram[0][0x40:0x50]); got != "a short         " {
+		t.Errorf("got %q on line 1, want %q", got, "a short         ")
+	}
+}
+
+func TestDisplayWrappedPads(t *testing.T) {
+	hd, _ := newTestDisplay(t)
+	_, err := hd.DisplayWrapped("a first line that's long", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = hd.DisplayWrapped("short", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(hd.ddram[0][0x00:0x10]); got != "short           " {
+		t.Errorf("got %q on the first line, want %q", got, "short           ")
+	}
+}
+
+func TestDisplayNumber(t *testing.T) {
+	hd, _ := newTestDisplay(t)
+
+	tests := []struct {
+		value    float64
+		decimals int
+		unit     string
+		want     string
+	}{
+		{21.56, 1, "C", " 21.6C"},
+		{-3, 0, "%", "   -3%"},
+		{1234.5, 1, "V", "######"},
+		{99.99, 2, "", " 99.99"},
+	}
+	for _, tt := range tests {
+		err := hd.DisplayNumber(tt.value, tt.decimals, tt.unit, 1, 4, 6)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(hd.ddram[0][0x44:0x4a]); got != tt.want {
+			t.Errorf("%v: got %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestDisplayLines(t *testing.T) {
+	hd, bus := newTestDisplay(t)
+
+	err := hd.DisplayLines("one\ntwo", 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"  one           ", "  two           "}
+	if got := rows(hd); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	bus.written = nil
+	err = hd.DisplayLines("one\ntwo", 1, 0)
+	if !errors.Is(err, ErrInvalidLine) {
+		t.Errorf("got %v with a line past the bottom, want ErrInvalidLine", err)
+	}
+	if len(bus.written) > 0 {
+		t.Errorf("got %d writes with a line past the bottom, want nothing sent", len(bus.written))
+	}
+}
@@ -0,0 +1,74 @@
+package hd44780
+
+import "time"
+
+// Timing is how long to wait for the controller at each step, a display that drops characters or instructions
+// may need longer delays. Start from DefaultTiming and change the ones that need changing, a zero delay doesn't wait
+// at all (apart from how long EN is held high).
+type Timing struct {
+	// Write is the wait after each instruction or character for the controller to carry it out, the datasheet
+	// gives 37µs for most instructions.
+	Write time.Duration
+	// EnableHigh is how long EN is held high, slow displays may need a wider pulse. It's never less than the
+	// datasheet's minimum of 450ns (at 3V, it's 230ns at 5V).
+	EnableHigh time.Duration
+	// Setup is the wait after setting up the data pins before EN goes high, and after EN goes low again before the
+	// next write. A bus write usually takes longer than the datasheet's setup and hold times so it's 0 by default.
+	Setup time.Duration
+	// Clear is the wait after clearing the display.
+	Clear time.Duration
+	// Home is the wait after returning the cursor home.
+	Home time.Duration
+}
+
+// DefaultTiming is the timing used unless SetTiming is given, it suits most displays.
+var DefaultTiming = Timing{
+	Write:      writeDelay,
+	EnableHigh: pulseDelay,
+	Clear:      clearDelay,
+	Home:       homeDelay,
+}
+
+// minEnableHigh is the shortest time EN is held high, the datasheet's minimum at 3V.
+const minEnableHigh = 450 * time.Nanosecond
+
+// enableHigh returns how long EN is held high for, EnableHigh but no less than minEnableHigh.
+func (hd *Hd44780I2c) enableHigh() time.Duration {
+	if hd.timing.EnableHigh < minEnableHigh {
+		return minEnableHigh
+	}
+	return hd.timing.EnableHigh
+}
+
+// pulseWait returns the wait after each of the writes of an EN pulse, the first sets up the data pins, the second
+// raises EN and the third lowers it.
+func (hd *Hd44780I2c) pulseWait(write int) time.Duration {
+	if write == 1 {
+		return hd.enableHigh()
+	}
+	return hd.timing.Setup
+}
+
+// SetTiming returns a ModeSetter that sets the delays used to wait for the controller.
+func SetTiming(t Timing) ModeSetter {
+	return func(hd *Hd44780I2c) { hd.timing = t }
+}
+
+// PollBusyFlag is a ModeSetter that makes the slow instructions (Clear and Home) wait by reading the busy flag until
+// the controller's finished, rather than always waiting as long as the slowest controller takes. It needs RW wired
+// to the port expander and a bus that implements io.Reader, without them it waits the fixed time.
+func PollBusyFlag(hd *Hd44780I2c) { hd.pollBusy = true }
+
+// Timing returns the delays currently used to wait for the controller.
+func (hd *Hd44780I2c) Timing() Timing {
+	return hd.timing
+}
+
+// WithTiming calls fn with the delays set to t and puts them back afterwards, so a single operation (eg loading
+// custom characters on a marginal display) can be slowed down without slowing everything else down.
+func (hd *Hd44780I2c) WithTiming(t Timing, fn func() error) error {
+	prev := hd.timing
+	hd.timing = t
+	defer func() { hd.timing = prev }()
+	return fn()
+}
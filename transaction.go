@@ -0,0 +1,25 @@
+package hd44780
+
+// Tx is the display during a Transaction, it has all the methods of Hd44780I2c. Calling Transaction on it runs the
+// nested transaction as part of this one. The helpers that take their own transactions (Spinner, Clock, Panel and
+// FrameBuffer) wait for the lock so they mustn't be used inside a transaction.
+type Tx struct {
+	*Hd44780I2c
+}
+
+// Transaction calls fn with tx, the lock is already held so nested transactions are just part of the outer one.
+func (tx *Tx) Transaction(fn func(tx *Tx) error) error {
+	return fn(tx)
+}
+
+// Transaction calls fn with the display locked so that everything fn writes goes to the display without writes from
+// other goroutines in between, eg updating several lines without a backlight toggle appearing half way through. The
+// methods of Hd44780I2c don't lock by themselves, only writes made in transactions are kept apart from each other
+// so a display shared between goroutines should only be written to in transactions. The error fn returns is
+// returned as is.
+func (hd *Hd44780I2c) Transaction(fn func(tx *Tx) error) error {
+	hd.mu.Lock()
+	defer hd.mu.Unlock()
+
+	return fn(&Tx{hd})
+}
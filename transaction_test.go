@@ -0,0 +1,69 @@
+package hd44780
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTransaction(t *testing.T) {
+	hd, bus := newTestDisplay(t)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for line, text := range []string{"aaaa", "bbbb"} {
+		wg.Add(1)
+		go func(line byte, text string) {
+			defer wg.Done()
+			errs <- hd.Transaction(func(tx *Tx) error {
+				err := tx.DisplayString(text, line, 0)
+				if err != nil {
+					return err
+				}
+				return tx.BacklightOff()
+			})
+		}(byte(line), text)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// each transaction's characters are sent together, in either order
+	a := []instruction{{registerSelectLow, lcdSetDDRamAddr | 0x00}}
+	b := []instruction{{registerSelectLow, lcdSetDDRamAddr | 0x40}}
+	for i := 0; i < 4; i++ {
+		a = append(a, instruction{registerSelectHigh, 'a'})
+		b = append(b, instruction{registerSelectHigh, 'b'})
+	}
+	got := bus.instructions(hd.PinMap)
+	if !reflect.DeepEqual(got, append(a, b...)) && !reflect.DeepEqual(got, append(b, a...)) {
+		t.Errorf("got instructions %#v, want the 2 transactions one after the other", got)
+	}
+}
+
+func TestNestedTransaction(t *testing.T) {
+	hd, _ := newTestDisplay(t)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- hd.Transaction(func(tx *Tx) error {
+			return tx.Transaction(func(tx *Tx) error { return tx.DisplayString("x", 0, 0) })
+		})
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("nested transaction deadlocked")
+	}
+	if hd.ddram[0][0x00] != 'x' {
+		t.Errorf("got %q at 0x00, want 'x'", hd.ddram[0][0x00])
+	}
+}